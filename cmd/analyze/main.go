@@ -0,0 +1,154 @@
+// Command analyze generates a web page report from the command line, for use in
+// shell scripts and CI pipelines.
+//
+// Usage:
+//
+//	analyze [flags] <url | file | ->
+//
+// The page is downloaded when the argument is an http or https URL, read from
+// stdin when it is "-" and read from a local file otherwise.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+type options struct {
+	format    string
+	baseURL   string
+	partial   bool
+	analyzers string
+	timeout   time.Duration
+	fetch     model.FetchOptions
+	// imageSizes requests every image to find out its size.
+	imageSizes bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.format, "format", "table", "output format: table, json or yaml")
+	flag.StringVar(&opts.baseURL, "base-url", "", "URL of the page read from a file or stdin, used to classify and check its links")
+	flag.BoolVar(&opts.partial, "partial", false, "report fields that cannot be computed as warnings instead of failing")
+	flag.StringVar(&opts.analyzers, "analyzers", "", "comma separated analyzers to run, all of them when empty")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "deadline for generating the report")
+	flag.StringVar(&opts.fetch.UserAgent, "user-agent", fetcher.DefaultUserAgent, "User-Agent the page is downloaded with")
+	flag.StringVar(&opts.fetch.Proxy, "proxy", "", "URL of the HTTP proxy the page is downloaded through")
+	flag.BoolVar(&opts.imageSizes, "image-sizes", false, "request every image to report its size and content type")
+	flag.Func("max-redirects", "number of redirects followed before giving up, 0 follows none (default 10)", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("max-redirects %q must be a number of at least 0", value)
+		}
+		opts.fetch.MaxRedirects = &n
+		return nil
+	})
+	flag.Func("header", "`name: value` header sent when downloading the page, can be repeated", func(header string) error {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("header %q must be formatted as name: value", header)
+		}
+		if opts.fetch.Headers == nil {
+			opts.fetch.Headers = map[string]string{}
+		}
+		opts.fetch.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		return nil
+	})
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags] <url | file | ->\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, flag.Arg(0), opts, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, source string, opts options, stdin io.Reader, stdout io.Writer) error {
+	write, ok := writers[opts.format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", opts.format)
+	}
+
+	pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{})
+	webParser := parser.NewWebPageParser(pageFetcher, parser.Config{})
+	var analyzersConfig parser.AnalyzersConfig
+	if opts.imageSizes {
+		analyzersConfig.Images.Fetcher = pageFetcher
+	}
+	analyzers, err := domain.NewRegistry(parser.BuiltInAnalyzers(pageFetcher), parser.Analyzers(analyzersConfig)...)
+	if err != nil {
+		return err
+	}
+	service := domain.NewService(webParser, analyzers, domain.Config{Timeout: opts.timeout})
+
+	reportOpts := model.ReportOptions{Partial: opts.partial, Fetch: opts.fetch}
+	if opts.analyzers != "" {
+		reportOpts.Analyzers = strings.Split(opts.analyzers, ",")
+	}
+
+	var report model.WebPageReport
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		report, err = service.GenerateWebPageReport(ctx, source, reportOpts)
+	} else {
+		report, err = generateLocalReport(ctx, service, webParser, source, opts.baseURL, reportOpts, stdin)
+	}
+	if errors.Is(err, domain.ErrAnalysisFailed) {
+		return fmt.Errorf("%w (use -partial to report it as a warning)", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return write(stdout, presenter.NewWebPageReportBody(report))
+}
+
+func generateLocalReport(
+	ctx context.Context,
+	service *domain.Service,
+	webParser *parser.WebPageParser,
+	source string,
+	baseURL string,
+	opts model.ReportOptions,
+	stdin io.Reader,
+) (model.WebPageReport, error) {
+	var content []byte
+	var err error
+	if source == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return model.WebPageReport{}, fmt.Errorf("could not read %v: %w", source, err)
+	}
+
+	document, err := webParser.FromString(string(content), baseURL)
+	if err != nil {
+		return model.WebPageReport{}, err
+	}
+	return service.GenerateDocumentReport(ctx, document, opts)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testPage = `<!DOCTYPE html><html><head><title>Test</title></head>
+<body><h1>Header</h1><a href="/home">Home</a></body></html>`
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	t.Run("should print the report of stdin as JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		opts := options{format: "json", baseURL: "http://localhost"}
+
+		err := run(context.Background(), "-", opts, strings.NewReader(testPage), &out)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var report struct {
+			Title             string `json:"title"`
+			HeaderOneCount    int    `json:"headerOneCount"`
+			InternalLinkCount int    `json:"internalLinkCount"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+			t.Fatalf("Output is not valid JSON: %v", err)
+		}
+		if report.Title != "Test" || report.HeaderOneCount != 1 || report.InternalLinkCount != 1 {
+			t.Fatalf("Unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("should return error for unknown formats", func(t *testing.T) {
+		err := run(context.Background(), "-", options{format: "xml"}, strings.NewReader(testPage), &bytes.Buffer{})
+
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+
+	t.Run("should return error when file does not exist", func(t *testing.T) {
+		err := run(context.Background(), "does-not-exist.html", options{format: "json"}, nil, &bytes.Buffer{})
+
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+}
+
+func TestWriteYAML(t *testing.T) {
+	t.Parallel()
+	v := map[string]any{
+		"empty":      []string{},
+		"links":      []map[string]any{{"url": "http://localhost", "statusCode": 404}},
+		"nested":     map[string]any{"count": 1, "title": "A \"quoted\" title"},
+		"properties": map[string]any{"a: b": 1, "- x": 2, "#x": 3, "": 4},
+	}
+	expected := `"empty": []
+"links":
+  - "statusCode": 404
+    "url": "http://localhost"
+"nested":
+  "count": 1
+  "title": "A \"quoted\" title"
+"properties":
+  "": 4
+  "#x": 3
+  "- x": 2
+  "a: b": 1
+`
+	var out bytes.Buffer
+
+	if err := writeYAML(&out, v); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%v\ngot:\n%v", expected, out.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	t.Parallel()
+	v := map[string]any{
+		"links": []map[string]any{{"url": "http://localhost"}},
+		"title": "Test",
+	}
+	var out bytes.Buffer
+
+	if err := writeTable(&out, v); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, row := range []string{"links[0].url  http://localhost", "title         Test"} {
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("Expected row %q in:\n%v", row, out.String())
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+type writer func(w io.Writer, v any) error
+
+var writers = map[string]writer{
+	"table": writeTable,
+	"json":  writeJSON,
+	"yaml":  writeYAML,
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeTable prints one row per value, flattening nested fields into paths
+// such as inaccessibleLinks[0].url.
+func writeTable(w io.Writer, v any) error {
+	value, err := toOrdered(v)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tVALUE")
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		switch value := value.(type) {
+		case *orderedMap:
+			if len(value.keys) == 0 {
+				fmt.Fprintf(tw, "%v\t%v\n", path, "{}")
+			}
+			for i, key := range value.keys {
+				if path == "" {
+					walk(key, value.values[i])
+				} else {
+					walk(path+"."+key, value.values[i])
+				}
+			}
+		case []any:
+			if len(value) == 0 {
+				fmt.Fprintf(tw, "%v\t%v\n", path, "[]")
+			}
+			for i, item := range value {
+				walk(fmt.Sprintf("%v[%d]", path, i), item)
+			}
+		case string:
+			fmt.Fprintf(tw, "%v\t%v\n", path, strings.TrimSpace(value))
+		case nil:
+			fmt.Fprintf(tw, "%v\t%v\n", path, "null")
+		default:
+			fmt.Fprintf(tw, "%v\t%v\n", path, value)
+		}
+	}
+	walk("", value)
+	return tw.Flush()
+}
+
+// writeYAML prints v as YAML. Strings, keys included, are always double quoted,
+// which makes JSON strings valid YAML scalars as they are. Keys must be quoted
+// as well, as some of them come from the page, such as the properties of its
+// structured data.
+func writeYAML(w io.Writer, v any) error {
+	value, err := toOrdered(v)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	writeYAMLValue(&b, value, 0)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeYAMLValue(b *strings.Builder, value any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch value := value.(type) {
+	case *orderedMap:
+		for i, key := range value.keys {
+			b.WriteString(pad + yamlScalar(key) + ":")
+			writeYAMLChild(b, value.values[i], indent+1)
+		}
+	case []any:
+		for _, item := range value {
+			b.WriteString(pad + "-")
+			if m, ok := item.(*orderedMap); ok && len(m.keys) > 0 {
+				// The first key goes on the same line as the dash.
+				var nested strings.Builder
+				writeYAMLValue(&nested, m, indent+1)
+				b.WriteString(" " + strings.TrimPrefix(nested.String(), pad+"  "))
+				continue
+			}
+			writeYAMLChild(b, item, indent+1)
+		}
+	default:
+		b.WriteString(pad + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLChild writes value after a "key:" or "-" that is already written.
+func writeYAMLChild(b *strings.Builder, value any, indent int) {
+	switch v := value.(type) {
+	case *orderedMap:
+		if len(v.keys) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLValue(b, v, indent)
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLValue(b, v, indent)
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// orderedMap is a JSON object that keeps the order of its keys, so that the
+// output follows the order of the report's fields.
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+// toOrdered converts v to its JSON representation made of *orderedMap, []any,
+// json.Number, string, bool and nil values.
+func toOrdered(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := &orderedMap{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.values = append(m.values, value)
+		}
+		_, err = dec.Token()
+		return m, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	default:
+		return token, nil
+	}
+}
@@ -1,34 +1,237 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
 	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
 	"github.com/G-Fuchter/home24-assignment/internal/adapters/http/handlers"
 	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/repository"
+	"github.com/G-Fuchter/home24-assignment/internal/application"
 	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
 	"github.com/labstack/echo/v4"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	e := echo.New()
 	cfg := http.Config{
 		Hostname: "",
 		Port:     "8080",
 	}
 	srv := http.NewServer(e, cfg)
-	handlers := getHandlers()
+	handlers, closeHandlers, err := getHandlers()
+	if err != nil {
+		fmt.Print(err.Error())
+		return
+	}
+	defer func() {
+		if err := closeHandlers(); err != nil {
+			fmt.Print(err.Error())
+		}
+	}()
 	srv.AddHandlers(handlers)
 	srv.EnableCORS()
 	srv.EnableStaticWebsite()
-	err := srv.Start()
-	fmt.Print(err.Error())
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Start()
+	}()
+	select {
+	case err := <-errs:
+		fmt.Print(err.Error())
+		return
+	case <-ctx.Done():
+	}
+	// Requests in flight are given some time to finish before the background
+	// jobs are cancelled and the reports are closed.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Print(err.Error())
+	}
 }
 
-func getHandlers() []http.Handler {
-	webParser := parser.NewWebPageParser()
-	service := domain.NewService(webParser)
+// getHandlers returns the handlers of the API, and a function that releases what
+// they use once the server is no longer serving them.
+func getHandlers() ([]http.Handler, func() error, error) {
+	pageFetcher, err := getFetcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	webParser := parser.NewWebPageParser(pageFetcher, parser.Config{
+		MaxBodySize:              int64(getEnvInt("MAX_DOCUMENT_SIZE", 10<<20)),
+		CertificateExpiryWarning: getEnvDuration("CERT_EXPIRY_WARNING", 30*24*time.Hour),
+	})
+	requiredProperties, err := getRequiredProperties()
+	if err != nil {
+		return nil, nil, err
+	}
+	analyzersConfig := parser.AnalyzersConfig{
+		StructuredData: parser.StructuredDataConfig{RequiredProperties: requiredProperties},
+	}
+	if getEnvBool("IMAGE_HEAD_REQUESTS", false) {
+		analyzersConfig.Images.Fetcher = pageFetcher
+	}
+	analyzers, err := domain.NewRegistry(parser.BuiltInAnalyzers(pageFetcher), parser.Analyzers(analyzersConfig)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	domainService := domain.NewService(webParser, analyzers, domain.Config{
+		Timeout:     getEnvDuration("REPORT_TIMEOUT", 30*time.Second),
+		Concurrency: getEnvInt("REPORT_CONCURRENCY", 0),
+	})
+	reports, err := getReportRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+	service := application.NewService(domainService, reports)
+	jobs := application.NewJobQueue(service, application.JobQueueConfig{
+		Workers:   getEnvInt("JOB_WORKERS", 4),
+		QueueSize: getEnvInt("JOB_QUEUE_SIZE", 100),
+	})
+	batches := application.NewBatchGenerator(service, application.BatchConfig{
+		Concurrency: getEnvInt("BATCH_CONCURRENCY", 4),
+		MaxSize:     getEnvInt("BATCH_MAX_SIZE", 50),
+		Timeout:     getEnvDuration("BATCH_TIMEOUT", 2*time.Minute),
+	})
 	return []http.Handler{
-		handlers.NewCreateWebPageReport(service),
+		handlers.NewCreateWebPageReport(service, jobs),
+		handlers.NewCreateWebPageReportBatch(batches),
+		handlers.NewStreamWebPageReport(domainService),
+		handlers.NewGetReportJob(jobs),
+		handlers.NewGetWebPageReport(service),
+		handlers.NewListWebPageReports(service),
+	}, func() error {
+		jobs.Close()
+		if closer, ok := reports.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}, nil
+}
+
+// getFetcher returns the fetcher pages are downloaded with, configured with
+// the server-wide defaults of every request. Callers can never reach the
+// private network of the server, unless it is allowed by URL_ALLOWLIST.
+func getFetcher() (*fetcher.HTTPFetcher, error) {
+	policy, err := fetcher.NewPolicy(fetcher.PolicyConfig{
+		Allow: getEnvList("URL_ALLOWLIST"),
+		Deny:  getEnvList("URL_DENYLIST"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 0 follows no redirects, so it must not fall back to the default.
+	maxRedirects := getEnvInt("FETCH_MAX_REDIRECTS", 10)
+	cfg := fetcher.Config{
+		Timeout:      getEnvDuration("FETCH_TIMEOUT", 15*time.Second),
+		UserAgent:    os.Getenv("FETCH_USER_AGENT"),
+		MaxRedirects: &maxRedirects,
+		Policy:       policy,
+	}
+	if proxy := os.Getenv("FETCH_PROXY"); proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FETCH_PROXY %q: %w", proxy, err)
+		}
+		cfg.Proxy = u
+	}
+	return fetcher.NewHTTPFetcher(cfg), nil
+}
+
+// getRequiredProperties reads the required properties of schema.org types from
+// SCHEMA_REQUIRED_PROPERTIES, a JSON object such as {"Product":["name"]}. The
+// defaults of the analyzer are used when it is not set.
+func getRequiredProperties() (map[string][]string, error) {
+	value := os.Getenv("SCHEMA_REQUIRED_PROPERTIES")
+	if value == "" {
+		return nil, nil
+	}
+	var properties map[string][]string
+	if err := json.Unmarshal([]byte(value), &properties); err != nil {
+		return nil, fmt.Errorf("invalid SCHEMA_REQUIRED_PROPERTIES: %w", err)
+	}
+	return properties, nil
+}
+
+// getReportRepository stores reports in the file at REPORT_STORE_FILE, or in
+// memory when it is not set.
+func getReportRepository() (ports.ReportRepository, error) {
+	path, ok := os.LookupEnv("REPORT_STORE_FILE")
+	if !ok || path == "" {
+		return repository.NewMemoryReportRepository(repository.MemoryConfig{
+			MaxReports: getEnvInt("REPORT_STORE_MAX", 1000),
+		}), nil
+	}
+	return repository.OpenFileReportRepository(path)
+}
+
+// getEnvDuration reads a duration such as "30s" from the environment, falling
+// back to def when the variable is not set or is not a valid duration.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("invalid %v %q, using %v\n", key, value, def)
+		return def
+	}
+	return d
+}
+
+// getEnvList reads a comma separated list from the environment.
+func getEnvList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// getEnvBool reads a boolean such as "true" from the environment, falling back
+// to def when the variable is not set or is not a valid boolean.
+func getEnvBool(key string, def bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("invalid %v %q, using %v\n", key, value, def)
+		return def
+	}
+	return b
+}
+
+// getEnvInt reads an integer from the environment, falling back to def when the
+// variable is not set or is not a valid integer.
+func getEnvInt(key string, def int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Printf("invalid %v %q, using %v\n", key, value, def)
+		return def
 	}
+	return i
 }
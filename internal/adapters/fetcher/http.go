@@ -0,0 +1,247 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"golang.org/x/net/http/httpproxy"
+)
+
+const DefaultUserAgent = "home24-assignment/1.0 (+https://github.com/G-Fuchter/home24-assignment)"
+const defaultTimeout = 15 * time.Second
+const defaultMaxRedirects = 10
+
+// Config holds the server-wide defaults of every request. Per request options
+// take precedence over them.
+type Config struct {
+	// Timeout is the deadline of a single request, body included.
+	Timeout   time.Duration
+	UserAgent string
+	Headers   map[string]string
+	// Proxy is the HTTP proxy requests are sent through. When it is nil, the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy *url.URL
+	// MaxRedirects is the number of redirects followed before giving up, which
+	// requests can lower. Nil follows 10, and 0 follows none.
+	MaxRedirects *int
+	// RootCAs are the certificate authorities HTTPS servers are verified
+	// against. Nil uses the ones of the system.
+	RootCAs *x509.CertPool
+	// Policy restricts the URLs that can be fetched, redirects included. Nil
+	// allows all of them. Requests sent through a proxy are only checked before
+	// they are sent, as the proxy resolves their host and the connection is
+	// made to the proxy, so they are not protected from DNS rebinding.
+	Policy *Policy
+}
+
+// HTTPFetcher implements the Fetcher interface with net/http.
+type HTTPFetcher struct {
+	cfg       Config
+	transport *http.Transport
+	// proxies are the addresses of the proxies of the server, which are trusted
+	// by the policy even when they are on a private network.
+	proxies map[string]bool
+}
+
+func NewHTTPFetcher(cfg Config) *HTTPFetcher {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.MaxRedirects == nil {
+		maxRedirects := defaultMaxRedirects
+		cfg.MaxRedirects = &maxRedirects
+	}
+	f := &HTTPFetcher{
+		cfg:     cfg,
+		proxies: serverProxies(cfg.Proxy),
+	}
+	f.transport = f.newTransport(cfg.Proxy, f.isServerProxy)
+	return f
+}
+
+// Fetch implements the Fetcher interface.
+func (f *HTTPFetcher) Fetch(ctx context.Context, method string, location string, opts model.FetchOptions) (*http.Response, error) {
+	transport := f.transport
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%w: proxy %q is not a valid URL", ports.ErrInvalidFetchOptions, opts.Proxy)
+		}
+		// Proxies of a single request are not worth keeping connections to, and
+		// are checked by the policy like any other address.
+		transport = f.newTransport(proxy, nil)
+		transport.DisableKeepAlives = true
+	}
+	timeout := f.cfg.Timeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	maxRedirects := max(*f.cfg.MaxRedirects, 0)
+	if opts.MaxRedirects != nil {
+		maxRedirects = max(min(*opts.MaxRedirects, maxRedirects), 0)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	req, err := http.NewRequestWithContext(ctx, method, location, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	f.setHeaders(req, opts)
+	if err := f.checkURL(req); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", ports.ErrTooManyRedirects, maxRedirects)
+			}
+			if req.URL.Host != via[0].URL.Host {
+				f.removeCredentials(req, opts)
+			}
+			return f.checkURL(req)
+		},
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline must outlive Fetch, as it also applies to reading the body.
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+func (f *HTTPFetcher) setHeaders(req *http.Request, opts model.FetchOptions) {
+	req.Header.Set("User-Agent", f.cfg.UserAgent)
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	for name, value := range f.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range opts.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	if opts.BasicAuth != nil {
+		req.SetBasicAuth(opts.BasicAuth.Username, opts.BasicAuth.Password)
+	}
+	if opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	}
+}
+
+// removeCredentials removes the headers, cookies and authentication of opts
+// from a redirect to another host, which they were not meant for. The headers
+// of the server are kept.
+func (f *HTTPFetcher) removeCredentials(req *http.Request, opts model.FetchOptions) {
+	for name := range opts.Headers {
+		req.Header.Del(name)
+	}
+	if len(opts.Cookies) > 0 {
+		req.Header.Del("Cookie")
+	}
+	if opts.BasicAuth != nil || opts.BearerToken != "" {
+		req.Header.Del("Authorization")
+	}
+	f.setHeaders(req, opts.WithoutCredentials())
+}
+
+// checkURL checks the URL of req against the policy before it is sent.
+func (f *HTTPFetcher) checkURL(req *http.Request) error {
+	if f.cfg.Policy == nil {
+		return nil
+	}
+	return f.cfg.Policy.CheckURL(req.Context(), req.URL)
+}
+
+// newTransport returns a transport that sends requests through proxy, or the
+// proxy of the environment when it is nil. Its connections are checked against
+// the policy, except for the addresses trusted returns true for.
+func (f *HTTPFetcher) newTransport(proxy *url.URL, trusted func(addr string) bool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	if f.cfg.RootCAs != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: f.cfg.RootCAs}
+	}
+	if f.cfg.Policy != nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		t.DialContext = f.cfg.Policy.DialContext(dialer, trusted)
+	}
+	return t
+}
+
+func (f *HTTPFetcher) isServerProxy(addr string) bool {
+	return f.proxies[addr]
+}
+
+// serverProxies returns the addresses of proxy, or of the proxies of the
+// environment when it is nil, as they are dialed.
+func serverProxies(proxy *url.URL) map[string]bool {
+	proxies := []*url.URL{proxy}
+	if proxy == nil {
+		env := httpproxy.FromEnvironment()
+		proxies = []*url.URL{parseProxy(env.HTTPProxy), parseProxy(env.HTTPSProxy)}
+	}
+	addrs := map[string]bool{}
+	for _, p := range proxies {
+		if p == nil || p.Hostname() == "" {
+			continue
+		}
+		port := p.Port()
+		if port == "" {
+			port = map[string]string{"https": "443", "socks5": "1080"}[p.Scheme]
+		}
+		if port == "" {
+			port = "80"
+		}
+		addrs[net.JoinHostPort(p.Hostname(), port)] = true
+	}
+	return addrs
+}
+
+// parseProxy parses a proxy from the environment, which may not have a scheme.
+func parseProxy(proxy string) *url.URL {
+	if proxy == "" {
+		return nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,269 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+	requests := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		cfg      fetcher.Config
+		opts     model.FetchOptions
+		expected func(r *http.Request) bool
+	}{
+		{
+			name:     "should send the default user agent",
+			expected: func(r *http.Request) bool { return r.UserAgent() == fetcher.DefaultUserAgent },
+		},
+		{
+			name: "should send the configured user agent and headers",
+			cfg:  fetcher.Config{UserAgent: "server", Headers: map[string]string{"Accept-Language": "de"}},
+			expected: func(r *http.Request) bool {
+				return r.UserAgent() == "server" && r.Header.Get("Accept-Language") == "de"
+			},
+		},
+		{
+			name: "should prefer the options of the request",
+			cfg:  fetcher.Config{UserAgent: "server", Headers: map[string]string{"Accept-Language": "de"}},
+			opts: model.FetchOptions{UserAgent: "request", Headers: map[string]string{"Accept-Language": "en"}},
+			expected: func(r *http.Request) bool {
+				return r.UserAgent() == "request" && r.Header.Get("Accept-Language") == "en"
+			},
+		},
+		{
+			name: "should send cookies",
+			opts: model.FetchOptions{Cookies: map[string]string{"session": "abc"}},
+			expected: func(r *http.Request) bool {
+				c, err := r.Cookie("session")
+				return err == nil && c.Value == "abc"
+			},
+		},
+		{
+			name: "should send basic auth",
+			opts: model.FetchOptions{BasicAuth: &model.BasicAuth{Username: "user", Password: "secret"}},
+			expected: func(r *http.Request) bool {
+				username, password, ok := r.BasicAuth()
+				return ok && username == "user" && password == "secret"
+			},
+		},
+		{
+			name:     "should send bearer tokens",
+			opts:     model.FetchOptions{BearerToken: "token"},
+			expected: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer token" },
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			f := fetcher.NewHTTPFetcher(tcase.cfg)
+
+			res, err := f.Fetch(context.Background(), http.MethodGet, srv.URL, tcase.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			res.Body.Close()
+
+			if r := <-requests; !tcase.expected(r) {
+				t.Fatalf("Unexpected request headers: %v", r.Header)
+			}
+		})
+	}
+}
+
+func TestFetch_Proxy(t *testing.T) {
+	t.Parallel()
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	t.Run("should send requests through the configured proxy", func(t *testing.T) {
+		f := fetcher.NewHTTPFetcher(fetcher.Config{Proxy: proxyURL})
+
+		res, err := f.Fetch(context.Background(), http.MethodGet, "http://home24.invalid/page", model.FetchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res.Body.Close()
+
+		if u := <-proxied; u != "http://home24.invalid/page" {
+			t.Fatalf("Expected the proxy to receive the request, got %v", u)
+		}
+	})
+
+	t.Run("should send requests through the proxy of the request", func(t *testing.T) {
+		f := fetcher.NewHTTPFetcher(fetcher.Config{})
+
+		res, err := f.Fetch(context.Background(), http.MethodGet, "http://home24.invalid/page", model.FetchOptions{Proxy: proxy.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res.Body.Close()
+
+		if u := <-proxied; u != "http://home24.invalid/page" {
+			t.Fatalf("Expected the proxy to receive the request, got %v", u)
+		}
+	})
+
+	t.Run("should return error for invalid proxies", func(t *testing.T) {
+		f := fetcher.NewHTTPFetcher(fetcher.Config{})
+
+		_, err := f.Fetch(context.Background(), http.MethodGet, "http://home24.invalid/page", model.FetchOptions{Proxy: "not a proxy"})
+
+		if !errors.Is(err, ports.ErrInvalidFetchOptions) {
+			t.Fatalf("Expected ErrInvalidFetchOptions, got %v", err)
+		}
+	})
+}
+
+func TestFetch_Timeout(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	f := fetcher.NewHTTPFetcher(fetcher.Config{Timeout: time.Minute})
+
+	_, err := f.Fetch(context.Background(), http.MethodGet, srv.URL, model.FetchOptions{Timeout: 50 * time.Millisecond})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the request to time out, got %v", err)
+	}
+}
+
+func TestFetch_Redirects(t *testing.T) {
+	t.Parallel()
+	// /3 redirects to /2, which redirects to /1 and so on until /0.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		cfg           fetcher.Config
+		opts          model.FetchOptions
+		expectedError error
+	}{
+		{
+			name: "should follow redirects up to the default limit",
+		},
+		{
+			name:          "should stop at the configured limit",
+			cfg:           fetcher.Config{MaxRedirects: intPtr(2)},
+			expectedError: ports.ErrTooManyRedirects,
+		},
+		{
+			name:          "should not follow redirects when the limit is 0",
+			cfg:           fetcher.Config{MaxRedirects: intPtr(0)},
+			expectedError: ports.ErrTooManyRedirects,
+		},
+		{
+			name: "should follow redirects up to the limit of the request",
+			opts: model.FetchOptions{MaxRedirects: intPtr(3)},
+		},
+		{
+			name:          "should stop at the limit of the request",
+			opts:          model.FetchOptions{MaxRedirects: intPtr(2)},
+			expectedError: ports.ErrTooManyRedirects,
+		},
+		{
+			name:          "should not follow redirects when the limit of the request is 0",
+			opts:          model.FetchOptions{MaxRedirects: intPtr(0)},
+			expectedError: ports.ErrTooManyRedirects,
+		},
+		{
+			name:          "should not exceed the limit of the server",
+			cfg:           fetcher.Config{MaxRedirects: intPtr(2)},
+			opts:          model.FetchOptions{MaxRedirects: intPtr(3)},
+			expectedError: ports.ErrTooManyRedirects,
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			f := fetcher.NewHTTPFetcher(tcase.cfg)
+
+			res, err := f.Fetch(context.Background(), http.MethodGet, srv.URL+"/3", tcase.opts)
+
+			if !errors.Is(err, tcase.expectedError) {
+				t.Fatalf("Expected error %v, got %v", tcase.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			res.Body.Close()
+			if res.Request.URL.Path != "/0" {
+				t.Fatalf("Expected to end at /0, got %v", res.Request.URL)
+			}
+		})
+	}
+}
+
+func TestFetch_CrossHostRedirect(t *testing.T) {
+	t.Parallel()
+	var received http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+	f := fetcher.NewHTTPFetcher(fetcher.Config{Headers: map[string]string{"X-Server": "server", "X-Api-Key": "server"}})
+	opts := model.FetchOptions{
+		Headers:     map[string]string{"X-Api-Key": "secret", "X-Custom": "secret"},
+		Cookies:     map[string]string{"session": "secret"},
+		BearerToken: "secret",
+	}
+
+	res, err := f.Fetch(context.Background(), http.MethodGet, srv.URL, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	for _, name := range []string{"X-Custom", "Cookie", "Authorization"} {
+		if value := received.Get(name); value != "" {
+			t.Errorf("Expected no %v header on the other host, got %q", name, value)
+		}
+	}
+	if value := received.Get("X-Api-Key"); value != "server" {
+		t.Errorf("Expected the X-Api-Key header of the server, got %q", value)
+	}
+	if value := received.Get("X-Server"); value != "server" {
+		t.Errorf("Expected the X-Server header of the server, got %q", value)
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
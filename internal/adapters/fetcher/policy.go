@@ -0,0 +1,200 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// blockedNetworks are not reachable from the internet, so that pages that are
+// publicly available never resolve to them. Loopback, private, link-local,
+// multicast and unspecified addresses are blocked as well.
+var blockedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Resolver looks up the addresses of a host. It is satisfied by *net.Resolver.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error)
+}
+
+type PolicyConfig struct {
+	// Allow lists hosts, such as "staging.internal", and networks, such as
+	// "10.1.0.0/16", that can be fetched even though they resolve to a blocked
+	// address. A host also matches its subdomains.
+	Allow []string
+	// Deny lists hosts and networks that can never be fetched, on top of the
+	// blocked ones. It takes precedence over Allow.
+	Deny []string
+	// Resolver defaults to net.DefaultResolver.
+	Resolver Resolver
+}
+
+// Policy decides which URLs can be fetched, to keep callers from reaching the
+// internal network of the server. Only http and https URLs are allowed, and
+// their hosts must not resolve to loopback, private or link-local addresses.
+type Policy struct {
+	allowHosts    []string
+	allowNetworks []netip.Prefix
+	denyHosts     []string
+	denyNetworks  []netip.Prefix
+	resolver      Resolver
+}
+
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	p := &Policy{resolver: cfg.Resolver}
+	if p.resolver == nil {
+		p.resolver = net.DefaultResolver
+	}
+	var err error
+	p.allowHosts, p.allowNetworks, err = parseRules(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+	p.denyHosts, p.denyNetworks, err = parseRules(cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseRules splits rules into hosts and networks. Single addresses are
+// networks of their own.
+func parseRules(rules []string) ([]string, []netip.Prefix, error) {
+	var hosts []string
+	var networks []netip.Prefix
+	for _, rule := range rules {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		switch {
+		case rule == "":
+		case strings.Contains(rule, "/"):
+			network, err := netip.ParsePrefix(rule)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid network %q: %w", rule, err)
+			}
+			networks = append(networks, network.Masked())
+		default:
+			if addr, err := netip.ParseAddr(strings.Trim(rule, "[]")); err == nil {
+				networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+			} else {
+				hosts = append(hosts, strings.TrimSuffix(rule, "."))
+			}
+		}
+	}
+	return hosts, networks, nil
+}
+
+// CheckURL returns an error wrapping ErrForbiddenURL when u cannot be fetched.
+// The host is resolved to check its addresses, but as they may change before
+// the request is sent, connections must be checked as well with DialContext.
+func (p *Policy) CheckURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ports.ErrForbiddenURL, u.Scheme)
+	}
+	_, err := p.resolve(ctx, u.Hostname())
+	return err
+}
+
+// DialContext returns a dial function that resolves the host itself and only
+// connects to addresses allowed by the policy, so that a host cannot resolve to
+// a different address between the check and the connection. Addresses for
+// which trusted returns true, such as the proxy of the server, are dialed as
+// they are. Behind a proxy only the proxy is dialed, so the host of the request
+// is not checked again.
+func (p *Policy) DialContext(dialer *net.Dialer, trusted func(addr string) bool) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		if trusted != nil && trusted(addr) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := p.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, a := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// resolve returns the addresses of host, or an error wrapping ErrForbiddenURL
+// when any of them cannot be fetched.
+func (p *Policy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return nil, fmt.Errorf("%w: missing host", ports.ErrForbiddenURL)
+	}
+	if matchesHost(p.denyHosts, host) {
+		return nil, fmt.Errorf("%w: host %v is denied", ports.ErrForbiddenURL, host)
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		addrs, err = p.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+	}
+	allowedHost := matchesHost(p.allowHosts, host)
+	for _, addr := range addrs {
+		if err := p.checkAddr(addr.Unmap(), allowedHost); err != nil {
+			return nil, fmt.Errorf("%w: %v resolves to %v", err, host, addr)
+		}
+	}
+	return addrs, nil
+}
+
+func (p *Policy) checkAddr(addr netip.Addr, allowedHost bool) error {
+	if matchesNetwork(p.denyNetworks, addr) {
+		return fmt.Errorf("%w: address is denied", ports.ErrForbiddenURL)
+	}
+	if allowedHost || matchesNetwork(p.allowNetworks, addr) {
+		return nil
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() ||
+		matchesNetwork(blockedNetworks, addr) {
+		return fmt.Errorf("%w: address is not public", ports.ErrForbiddenURL)
+	}
+	return nil
+}
+
+func matchesHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNetwork(networks []netip.Prefix, addr netip.Addr) bool {
+	for _, n := range networks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
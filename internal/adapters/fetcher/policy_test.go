@@ -0,0 +1,154 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// stubResolver resolves every host to the next of its addresses, and then to
+// the last one over and over.
+type stubResolver struct {
+	addrs []netip.Addr
+	calls atomic.Int32
+}
+
+func (r *stubResolver) LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+	i := min(int(r.calls.Add(1))-1, len(r.addrs)-1)
+	return []netip.Addr{r.addrs[i]}, nil
+}
+
+func resolveTo(addrs ...string) *stubResolver {
+	r := &stubResolver{}
+	for _, a := range addrs {
+		r.addrs = append(r.addrs, netip.MustParseAddr(a))
+	}
+	return r
+}
+
+func TestCheckURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		location  string
+		cfg       fetcher.PolicyConfig
+		forbidden bool
+	}{
+		{location: "http://93.184.215.14/", forbidden: false},
+		{location: "https://home24.de/", cfg: fetcher.PolicyConfig{Resolver: resolveTo("93.184.215.14")}, forbidden: false},
+		{location: "ftp://home24.de/", cfg: fetcher.PolicyConfig{Resolver: resolveTo("93.184.215.14")}, forbidden: true},
+		{location: "http://127.0.0.1:8080/", forbidden: true},
+		{location: "http://[::1]/", forbidden: true},
+		{location: "http://[::ffff:127.0.0.1]/", forbidden: true},
+		{location: "http://169.254.169.254/latest/meta-data/", forbidden: true},
+		{location: "http://10.0.0.1/", forbidden: true},
+		{location: "http://172.16.0.1/", forbidden: true},
+		{location: "http://192.168.1.1/", forbidden: true},
+		{location: "http://100.64.0.1/", forbidden: true},
+		{location: "http://0.0.0.0/", forbidden: true},
+		{location: "http://internal.home24.de/", cfg: fetcher.PolicyConfig{Resolver: resolveTo("10.0.0.1")}, forbidden: true},
+		{location: "http://10.1.2.3/", cfg: fetcher.PolicyConfig{Allow: []string{"10.1.0.0/16"}}, forbidden: false},
+		{location: "http://staging.internal/", cfg: fetcher.PolicyConfig{Allow: []string{"internal"}, Resolver: resolveTo("10.0.0.1")}, forbidden: false},
+		{location: "http://10.1.2.3/", cfg: fetcher.PolicyConfig{Allow: []string{"10.1.0.0/16"}, Deny: []string{"10.1.2.3"}}, forbidden: true},
+		{location: "https://shop.home24.de/", cfg: fetcher.PolicyConfig{Deny: []string{"home24.de"}, Resolver: resolveTo("93.184.215.14")}, forbidden: true},
+		{location: "https://93.184.215.14/", cfg: fetcher.PolicyConfig{Deny: []string{"93.184.215.0/24"}}, forbidden: true},
+	}
+	for _, tcase := range tests {
+		t.Run(fmt.Sprintf("%v %+v", tcase.location, tcase.cfg.Allow), func(t *testing.T) {
+			policy, err := fetcher.NewPolicy(tcase.cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			u, _ := url.Parse(tcase.location)
+
+			err = policy.CheckURL(context.Background(), u)
+
+			if forbidden := errors.Is(err, ports.ErrForbiddenURL); forbidden != tcase.forbidden {
+				t.Fatalf("Expected forbidden to be %v, got %v", tcase.forbidden, err)
+			}
+		})
+	}
+
+	t.Run("should return error for invalid networks", func(t *testing.T) {
+		_, err := fetcher.NewPolicy(fetcher.PolicyConfig{Allow: []string{"10.0.0.0/33"}})
+
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+}
+
+func TestFetch_Policy(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://127.0.0.1/admin", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	port := srv.URL[len("http://127.0.0.1:"):]
+	// The server is only reachable through its localhost name, which is allowed
+	// as if it was public, while its address is not.
+	location := "http://localhost:" + port
+
+	fetch := func(policy fetcher.PolicyConfig, location string) error {
+		p, err := fetcher.NewPolicy(policy)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res, err := fetcher.NewHTTPFetcher(fetcher.Config{Policy: p}).Fetch(context.Background(), http.MethodGet, location, model.FetchOptions{})
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("should fetch allowed hosts", func(t *testing.T) {
+		err := fetch(fetcher.PolicyConfig{Allow: []string{"localhost"}}, location)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should not follow redirects to forbidden URLs", func(t *testing.T) {
+		err := fetch(fetcher.PolicyConfig{Allow: []string{"localhost"}}, location+"/redirect")
+
+		if !errors.Is(err, ports.ErrForbiddenURL) {
+			t.Fatalf("Expected ErrForbiddenURL, got %v", err)
+		}
+	})
+
+	t.Run("should not connect to hosts that resolve to a forbidden address after being checked", func(t *testing.T) {
+		resolver := resolveTo("93.184.215.14", "127.0.0.1")
+
+		err := fetch(fetcher.PolicyConfig{Resolver: resolver}, "http://rebinding.home24.de:"+port)
+
+		if !errors.Is(err, ports.ErrForbiddenURL) {
+			t.Fatalf("Expected ErrForbiddenURL, got %v", err)
+		}
+		if resolver.calls.Load() != 2 {
+			t.Fatalf("Expected the host to be resolved again when connecting, got %d lookups", resolver.calls.Load())
+		}
+	})
+
+	t.Run("should check the proxies of a request", func(t *testing.T) {
+		p, _ := fetcher.NewPolicy(fetcher.PolicyConfig{Resolver: resolveTo("93.184.215.14")})
+		f := fetcher.NewHTTPFetcher(fetcher.Config{Policy: p})
+
+		_, err := f.Fetch(context.Background(), http.MethodGet, "http://home24.de/", model.FetchOptions{Proxy: srv.URL})
+
+		if !errors.Is(err, ports.ErrForbiddenURL) {
+			t.Fatalf("Expected ErrForbiddenURL, got %v", err)
+		}
+	})
+}
@@ -23,6 +23,9 @@ type Context interface {
 	// SetRequest sets `*http.Request`.
 	SetRequest(r *http.Request)
 
+	// Response returns the response the handler writes to.
+	Response() Response
+
 	// Param returns path parameter by name.
 	Param(name string) string
 
@@ -145,6 +148,16 @@ type Context interface {
 	Error(err error)
 }
 
+// Response is what handlers that write their response themselves, such as
+// streams, need of it.
+type Response interface {
+	Header() http.Header
+	WriteHeader(code int)
+	Write(b []byte) (int, error)
+	// Flush sends the data written so far to the client.
+	Flush()
+}
+
 type Handler interface {
 	GetMethod() Method
 	GetEndpoint() string
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	httpgo "net/http"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+type PostWebPageReportBatchRequestBody struct {
+	URLs      []string         `json:"urls"`
+	Partial   bool             `json:"partial"`
+	Analyzers []string         `json:"analyzers"`
+	Fetch     FetchOptionsBody `json:"fetch"`
+}
+
+type PostWebPageReportBatchResponseBody struct {
+	Results []BatchResultBody `json:"results"`
+}
+
+// BatchResultBody holds either the report of a URL or the problem that
+// prevented it from being generated.
+type BatchResultBody struct {
+	URL    string                       `json:"url"`
+	Report *presenter.WebPageReportBody `json:"report,omitempty"`
+	Error  *Problem                     `json:"error,omitempty"`
+}
+
+type CreateWebPageReportBatch struct {
+	batchService ports.BatchService
+}
+
+func NewCreateWebPageReportBatch(batchService ports.BatchService) *CreateWebPageReportBatch {
+	return &CreateWebPageReportBatch{
+		batchService: batchService,
+	}
+}
+
+func (h *CreateWebPageReportBatch) GetMethod() http.Method {
+	return http.Post
+}
+
+func (h *CreateWebPageReportBatch) GetEndpoint() string {
+	return "/reports/webpage/batch"
+}
+
+func (h *CreateWebPageReportBatch) Handle(c http.Context) error {
+	var body PostWebPageReportBatchRequestBody
+	err := c.Bind(&body)
+	if err != nil {
+		return writeProblem(c, newInvalidRequestProblem(err.Error()))
+	}
+	if len(body.URLs) == 0 {
+		return writeProblem(c, newInvalidRequestProblem("urls must not be empty"))
+	}
+	fetch, err := body.Fetch.options()
+	if err != nil {
+		return writeProblem(c, newInvalidRequestProblem(err.Error()))
+	}
+	opts := model.ReportOptions{
+		Partial:   body.Partial,
+		Analyzers: body.Analyzers,
+		Fetch:     fetch,
+	}
+	results, err := h.batchService.GenerateWebPageReports(c.Request().Context(), body.URLs, opts)
+	if err != nil {
+		return writeError(c, err)
+	}
+	resBody := &PostWebPageReportBatchResponseBody{
+		Results: []BatchResultBody{},
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			logError(c, fmt.Errorf("%v: %w", result.URL, result.Err))
+		}
+		resBody.Results = append(resBody.Results, newBatchResultBody(result))
+	}
+	return c.JSON(httpgo.StatusOK, resBody)
+}
+
+func newBatchResultBody(result model.BatchResult) BatchResultBody {
+	if result.Err != nil {
+		problem := NewProblem(result.Err)
+		return BatchResultBody{URL: result.URL, Error: &problem}
+	}
+	return BatchResultBody{URL: result.URL, Report: presenter.NewWebPageReportBody(result.Report)}
+}
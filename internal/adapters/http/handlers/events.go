@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	httpgo "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// Names of the Server-Sent Events sent while a report is generated.
+const (
+	EventProgress = "progress"
+	EventReport   = "report"
+	EventProblem  = "problem"
+)
+
+type ProgressEventBody struct {
+	Stage string `json:"stage"`
+}
+
+// StreamWebPageReport generates a report like CreateWebPageReport does, but
+// streams its progress as Server-Sent Events. It is a GET endpoint so that
+// browsers can consume it with an EventSource, which is why the report is not
+// stored.
+type StreamWebPageReport struct {
+	reportGenerator ports.ReportGenerator
+}
+
+func NewStreamWebPageReport(reportGenerator ports.ReportGenerator) *StreamWebPageReport {
+	return &StreamWebPageReport{
+		reportGenerator: reportGenerator,
+	}
+}
+
+func (h *StreamWebPageReport) GetMethod() http.Method {
+	return http.Get
+}
+
+func (h *StreamWebPageReport) GetEndpoint() string {
+	return "/reports/webpage/events"
+}
+
+func (h *StreamWebPageReport) Handle(c http.Context) error {
+	opts, err := streamReportOptions(c)
+	if err != nil {
+		return writeProblem(c, newInvalidRequestProblem(err.Error()))
+	}
+	location := c.QueryParam("url")
+	// Invalid requests are answered like any other request. Every later error,
+	// such as the page not being downloaded, is sent as an event, as
+	// EventSource cannot read the body of a failed response.
+	if err := domain.ValidateLocation(location); err != nil {
+		return writeError(c, err)
+	}
+	if err := h.reportGenerator.ValidateOptions(opts); err != nil {
+		return writeError(c, err)
+	}
+	stream := &eventStream{c: c}
+	stream.start()
+	opts.Progress = func(stage model.ReportStage) {
+		stream.send(EventProgress, ProgressEventBody{Stage: string(stage)})
+	}
+
+	report, err := h.reportGenerator.GenerateWebPageReport(c.Request().Context(), location, opts)
+	if err != nil {
+		logError(c, err)
+		return stream.send(EventProblem, NewProblem(err))
+	}
+	return stream.send(EventReport, presenter.NewWebPageReportBody(report))
+}
+
+// streamReportOptions reads the report options from the query parameters.
+// Analyzers are comma separated, and like in the request body all of them run
+// when the parameter is omitted and none when it is empty.
+func streamReportOptions(c http.Context) (model.ReportOptions, error) {
+	var opts model.ReportOptions
+	if partial := c.QueryParam("partial"); partial != "" {
+		var err error
+		opts.Partial, err = strconv.ParseBool(partial)
+		if err != nil {
+			return model.ReportOptions{}, fmt.Errorf("invalid partial %q", partial)
+		}
+	}
+	if names, ok := c.QueryParams()["analyzers"]; ok {
+		opts.Analyzers = []string{}
+		for _, name := range strings.Split(strings.Join(names, ","), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Analyzers = append(opts.Analyzers, name)
+			}
+		}
+	}
+	return opts, nil
+}
+
+// eventStream writes Server-Sent Events.
+type eventStream struct {
+	c http.Context
+}
+
+// start sends the headers of the stream, so that the client knows it started
+// before the first event.
+func (s *eventStream) start() {
+	res := s.c.Response()
+	res.Header().Set("Content-Type", eventStreamContentType)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(httpgo.StatusOK)
+	res.Flush()
+}
+
+func (s *eventStream) send(event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	res := s.c.Response()
+	if _, err := fmt.Fprintf(res, "event: %v\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
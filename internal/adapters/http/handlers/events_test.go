@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"context"
+	httpgo "net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http/handlers"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/labstack/echo/v4"
+)
+
+// stagesGenerator reports every stage before failing or returning the report,
+// unless downloadErr is set, in which case it fails before reporting any stage.
+type stagesGenerator struct {
+	err         error
+	downloadErr error
+}
+
+func (g stagesGenerator) ValidateOptions(opts model.ReportOptions) error {
+	if slices.Contains(opts.Analyzers, "unknown") {
+		return domain.ErrUnknownAnalyzer
+	}
+	return nil
+}
+
+func (g stagesGenerator) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	if err := domain.ValidateLocation(location); err != nil {
+		return model.WebPageReport{}, err
+	}
+	if g.downloadErr != nil {
+		return model.WebPageReport{}, g.downloadErr
+	}
+	opts.Progress(model.StageDownloaded)
+	opts.Progress(model.StageTitle)
+	if g.err != nil {
+		return model.WebPageReport{}, g.err
+	}
+	return model.WebPageReport{URL: location, Title: "Title"}, nil
+}
+
+func TestStreamWebPageReport(t *testing.T) {
+	t.Parallel()
+	stream := func(generator stagesGenerator, target string) *httptest.ResponseRecorder {
+		e := echo.New()
+		http.NewServer(e, http.Config{}).AddHandlers([]http.Handler{handlers.NewStreamWebPageReport(generator)})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(httpgo.MethodGet, target, nil))
+		return rec
+	}
+
+	t.Run("should stream the progress and then the report", func(t *testing.T) {
+		rec := stream(stagesGenerator{}, "/reports/webpage/events?url=http://localhost")
+
+		if rec.Code != httpgo.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %v %v", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 events, got %q", events)
+		}
+		expected := []string{
+			"event: progress\ndata: {\"stage\":\"downloaded\"}",
+			"event: progress\ndata: {\"stage\":\"title\"}",
+		}
+		for i, e := range expected {
+			if events[i] != e {
+				t.Errorf("Expected event %q, got %q", e, events[i])
+			}
+		}
+		if !strings.HasPrefix(events[2], "event: report\ndata: {\"url\":") || !strings.Contains(events[2], `"title":"Title"`) {
+			t.Errorf("Expected the report, without an ID, as the last event, got %q", events[2])
+		}
+	})
+
+	t.Run("should stream errors found after the stream started", func(t *testing.T) {
+		rec := stream(stagesGenerator{err: domain.ErrAnalysisFailed}, "/reports/webpage/events?url=http://localhost")
+
+		events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+		last := events[len(events)-1]
+		if !strings.HasPrefix(last, "event: problem\n") || !strings.Contains(last, `"code":"analysis_failed"`) {
+			t.Fatalf("Expected a problem event last, got %q", last)
+		}
+	})
+
+	t.Run("should stream errors found before any progress", func(t *testing.T) {
+		rec := stream(stagesGenerator{downloadErr: ports.ErrUnreachableHost}, "/reports/webpage/events?url=http://localhost")
+
+		if rec.Code != httpgo.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %v %v", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+		if len(events) != 1 || !strings.HasPrefix(events[0], "event: problem\n") || !strings.Contains(events[0], `"code":"unreachable_host"`) {
+			t.Fatalf("Expected a single problem event, got %q", events)
+		}
+	})
+
+	t.Run("should answer with a problem before the stream started", func(t *testing.T) {
+		rec := stream(stagesGenerator{}, "/reports/webpage/events?url=localhost")
+
+		if rec.Code != httpgo.StatusBadRequest || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("Expected a problem, got %v %v", rec.Code, rec.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("should reject invalid options", func(t *testing.T) {
+		rec := stream(stagesGenerator{}, "/reports/webpage/events?url=http://localhost&partial=maybe")
+
+		if rec.Code != httpgo.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %v", rec.Code)
+		}
+	})
+
+	t.Run("should reject unknown analyzers before the stream started", func(t *testing.T) {
+		rec := stream(stagesGenerator{}, "/reports/webpage/events?url=http://localhost&analyzers=unknown")
+
+		if rec.Code != httpgo.StatusBadRequest || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("Expected a problem, got %v %v", rec.Code, rec.Header().Get("Content-Type"))
+		}
+	})
+}
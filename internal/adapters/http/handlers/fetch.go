@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// FetchOptionsBody changes how the page is downloaded. Empty fields fall back
+// to the server-wide defaults.
+type FetchOptionsBody struct {
+	// Timeout is a duration such as "10s".
+	Timeout     string            `json:"timeout"`
+	UserAgent   string            `json:"userAgent"`
+	Headers     map[string]string `json:"headers"`
+	Cookies     map[string]string `json:"cookies"`
+	BasicAuth   *BasicAuthBody    `json:"basicAuth"`
+	BearerToken string            `json:"bearerToken"`
+	Proxy       string            `json:"proxy"`
+	// MaxRedirects is the number of redirects followed before giving up, at
+	// most the limit of the server. 0 follows none.
+	MaxRedirects *int `json:"maxRedirects"`
+}
+
+type BasicAuthBody struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (b FetchOptionsBody) options() (model.FetchOptions, error) {
+	opts := model.FetchOptions{
+		UserAgent:    b.UserAgent,
+		Headers:      b.Headers,
+		Cookies:      b.Cookies,
+		BearerToken:  b.BearerToken,
+		Proxy:        b.Proxy,
+		MaxRedirects: b.MaxRedirects,
+	}
+	if b.Timeout != "" {
+		timeout, err := time.ParseDuration(b.Timeout)
+		if err != nil || timeout <= 0 {
+			return model.FetchOptions{}, fmt.Errorf("invalid fetch timeout %q", b.Timeout)
+		}
+		opts.Timeout = timeout
+	}
+	if b.MaxRedirects != nil && *b.MaxRedirects < 0 {
+		return model.FetchOptions{}, fmt.Errorf("invalid fetch maxRedirects %d", *b.MaxRedirects)
+	}
+	if b.BasicAuth != nil {
+		opts.BasicAuth = &model.BasicAuth{
+			Username: b.BasicAuth.Username,
+			Password: b.BasicAuth.Password,
+		}
+	}
+	return opts, nil
+}
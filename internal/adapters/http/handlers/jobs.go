@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	httpgo "net/http"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+type ReportJobResponseBody struct {
+	ID         string `json:"id"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	CreatedAt  string `json:"createdAt"`
+	StartedAt  string `json:"startedAt,omitempty"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+
+	Report *presenter.WebPageReportBody `json:"report,omitempty"`
+	Error  *Problem                     `json:"error,omitempty"`
+}
+
+// NewReportJobResponseBody maps a job to its JSON representation. Failed jobs
+// carry the same problem details the synchronous report would have responded with.
+func NewReportJobResponseBody(job model.ReportJob) *ReportJobResponseBody {
+	resBody := &ReportJobResponseBody{
+		ID:         job.ID,
+		URL:        job.URL,
+		State:      string(job.State),
+		CreatedAt:  presenter.FormatTime(job.CreatedAt),
+		StartedAt:  presenter.FormatTime(job.StartedAt),
+		FinishedAt: presenter.FormatTime(job.FinishedAt),
+	}
+	switch job.State {
+	case model.JobStateSucceeded:
+		resBody.Report = presenter.NewWebPageReportBody(job.Report)
+	case model.JobStateFailed:
+		problem := NewProblem(job.Err)
+		resBody.Error = &problem
+	}
+	return resBody
+}
+
+type GetReportJob struct {
+	jobService ports.JobService
+}
+
+func NewGetReportJob(jobService ports.JobService) *GetReportJob {
+	return &GetReportJob{
+		jobService: jobService,
+	}
+}
+
+func (h *GetReportJob) GetMethod() http.Method {
+	return http.Get
+}
+
+func (h *GetReportJob) GetEndpoint() string {
+	return "/jobs/:id"
+}
+
+func (h *GetReportJob) Handle(c http.Context) error {
+	job, err := h.jobService.GetReportJob(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(httpgo.StatusOK, NewReportJobResponseBody(job))
+}
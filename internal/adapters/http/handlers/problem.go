@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	httpgo "net/http"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is a stable identifier
+// that API consumers can rely on, unlike Title and Detail which are meant for humans.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+}
+
+type problemType struct {
+	err    error
+	code   string
+	status int
+	title  string
+	// detail is written for the code rather than taken from the error, which
+	// may hold internal addresses or the rules of the URL policy.
+	detail string
+}
+
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeInvalidURL          = "invalid_url"
+	CodeUnknownAnalyzer     = "unknown_analyzer"
+	CodeReportNotFound      = "report_not_found"
+	CodeJobNotFound         = "job_not_found"
+	CodeQueueFull           = "queue_full"
+	CodeBatchTooLarge       = "batch_too_large"
+	CodeInvalidFetchOptions = "invalid_fetch_options"
+	CodeForbiddenURL        = "forbidden_url"
+	CodeTooManyRedirects    = "too_many_redirects"
+	CodeUnreachableHost     = "unreachable_host"
+	CodeUpstreamClientError = "upstream_client_error"
+	CodeUpstreamServerError = "upstream_server_error"
+	CodeNotHTML             = "not_html"
+	CodeTooLarge            = "too_large"
+	CodeTimeout             = "timeout"
+	CodeAnalysisFailed      = "analysis_failed"
+	CodeInternalError       = "internal_error"
+)
+
+// problemTypes is checked in order, so more specific errors must come first.
+var problemTypes = []problemType{
+	{domain.ErrTimeout, CodeTimeout, httpgo.StatusGatewayTimeout, "The report could not be generated in time",
+		"The page could not be downloaded and analysed before the deadline of the report."},
+	{domain.ErrInvalidURL, CodeInvalidURL, httpgo.StatusBadRequest, "The URL is not valid",
+		"The URL must be an absolute http or https URL."},
+	{domain.ErrUnknownAnalyzer, CodeUnknownAnalyzer, httpgo.StatusBadRequest, "The analyzer does not exist",
+		"One of the requested analyzers is not registered on the server."},
+	{ports.ErrReportNotFound, CodeReportNotFound, httpgo.StatusNotFound, "The report does not exist",
+		"There is no stored report with this ID."},
+	{ports.ErrJobNotFound, CodeJobNotFound, httpgo.StatusNotFound, "The job does not exist",
+		"There is no job with this ID, or it finished too long ago to be kept."},
+	{ports.ErrQueueFull, CodeQueueFull, httpgo.StatusServiceUnavailable, "Too many reports are queued, try again later",
+		"The queue of asynchronous reports is full."},
+	{ports.ErrBatchTooLarge, CodeBatchTooLarge, httpgo.StatusBadRequest, "The batch has too many URLs",
+		"The batch has more URLs than the server accepts at once."},
+	{ports.ErrInvalidFetchOptions, CodeInvalidFetchOptions, httpgo.StatusBadRequest, "The fetch options are not valid",
+		"The fetch options cannot be used, such as a proxy that is not a valid URL."},
+	{ports.ErrForbiddenURL, CodeForbiddenURL, httpgo.StatusForbidden, "The URL is not allowed",
+		"The URL, or one it redirects to, is not allowed to be fetched by the server."},
+	{ports.ErrTooManyRedirects, CodeTooManyRedirects, httpgo.StatusBadGateway, "The page redirected too many times",
+		"The page redirected more times than the server follows."},
+	{ports.ErrUnreachableHost, CodeUnreachableHost, httpgo.StatusBadGateway, "The host could not be reached",
+		"The host could not be resolved, or did not answer."},
+	{ports.ErrUpstreamClientError, CodeUpstreamClientError, httpgo.StatusBadGateway, "The page responded with a client error",
+		"The page responded with a 4xx status code."},
+	{ports.ErrUpstreamServerError, CodeUpstreamServerError, httpgo.StatusBadGateway, "The page responded with a server error",
+		"The page responded with a 5xx status code."},
+	{ports.ErrNotHTML, CodeNotHTML, httpgo.StatusUnprocessableEntity, "The page is not an HTML document",
+		"The page is not served as text/html or application/xhtml+xml."},
+	{ports.ErrDocumentTooLarge, CodeTooLarge, httpgo.StatusUnprocessableEntity, "The page is too large",
+		"The page is larger than the server downloads."},
+	{domain.ErrAnalysisFailed, CodeAnalysisFailed, httpgo.StatusUnprocessableEntity, "The page could not be analysed",
+		"A field of the report could not be computed. Partial reports list such fields as warnings instead."},
+}
+
+var internalProblemType = problemType{
+	code:   CodeInternalError,
+	status: httpgo.StatusInternalServerError,
+	title:  "Something went wrong while generating the report",
+}
+
+// NewProblem maps an error returned by the services to the problem that
+// describes it. The error itself is never part of the problem, to avoid
+// leaking anything about the server, so it must be logged with logError
+// instead. Errors that are not known are reported as internal errors.
+func NewProblem(err error) Problem {
+	for _, t := range problemTypes {
+		if errors.Is(err, t.err) {
+			return t.problem(t.detail)
+		}
+	}
+	return internalProblemType.problem("")
+}
+
+// writeError logs err and answers with the problem that describes it.
+func writeError(c http.Context, err error) error {
+	logError(c, err)
+	return writeProblem(c, NewProblem(err))
+}
+
+// logError logs an error of the request of c on the server, where its details
+// can be looked into without being sent to the client.
+func logError(c http.Context, err error) {
+	log.Printf("%v %v: %v", c.Request().Method, c.Request().URL.Path, err)
+}
+
+func newInvalidRequestProblem(detail string) Problem {
+	t := problemType{
+		code:   CodeInvalidRequest,
+		status: httpgo.StatusBadRequest,
+		title:  "The request body is not valid",
+	}
+	return t.problem(detail)
+}
+
+func (t problemType) problem(detail string) Problem {
+	return Problem{
+		Type:   "urn:home24-assignment:problem:" + t.code,
+		Title:  t.title,
+		Status: t.status,
+		Detail: detail,
+		Code:   t.code,
+	}
+}
+
+func writeProblem(c http.Context, p Problem) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Blob(p.Status, problemContentType, b)
+}
@@ -0,0 +1,189 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	httpgo "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http/handlers"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/labstack/echo/v4"
+)
+
+func TestNewProblem(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "invalid URL",
+			err:            fmt.Errorf("%w: %w", domain.ErrInvlidPage, domain.ErrInvalidURL),
+			expectedCode:   handlers.CodeInvalidURL,
+			expectedStatus: httpgo.StatusBadRequest,
+		},
+		{
+			name:           "unreachable host",
+			err:            fmt.Errorf("%w: %w: dial tcp 10.0.0.5:80: connection refused", domain.ErrInvlidPage, ports.ErrUnreachableHost),
+			expectedCode:   handlers.CodeUnreachableHost,
+			expectedStatus: httpgo.StatusBadGateway,
+		},
+		{
+			name:           "forbidden URL",
+			err:            fmt.Errorf("%w: %w: %w: address is not public", domain.ErrInvlidPage, errors.New("could not load document"), ports.ErrForbiddenURL),
+			expectedCode:   handlers.CodeForbiddenURL,
+			expectedStatus: httpgo.StatusForbidden,
+		},
+		{
+			name:           "too many redirects",
+			err:            fmt.Errorf("%w: %w: %w: stopped after 10 redirects", domain.ErrInvlidPage, errors.New("could not load document"), ports.ErrTooManyRedirects),
+			expectedCode:   handlers.CodeTooManyRedirects,
+			expectedStatus: httpgo.StatusBadGateway,
+		},
+		{
+			name:           "upstream client error",
+			err:            fmt.Errorf("%w: %w: 404 Not Found", domain.ErrInvlidPage, ports.ErrUpstreamClientError),
+			expectedCode:   handlers.CodeUpstreamClientError,
+			expectedStatus: httpgo.StatusBadGateway,
+		},
+		{
+			name:           "upstream server error",
+			err:            fmt.Errorf("%w: %w: 503 Service Unavailable", domain.ErrInvlidPage, ports.ErrUpstreamServerError),
+			expectedCode:   handlers.CodeUpstreamServerError,
+			expectedStatus: httpgo.StatusBadGateway,
+		},
+		{
+			name:           "not HTML",
+			err:            fmt.Errorf("%w: %w", domain.ErrInvlidPage, ports.ErrNotHTML),
+			expectedCode:   handlers.CodeNotHTML,
+			expectedStatus: httpgo.StatusUnprocessableEntity,
+		},
+		{
+			name:           "too large",
+			err:            fmt.Errorf("%w: %w", domain.ErrInvlidPage, ports.ErrDocumentTooLarge),
+			expectedCode:   handlers.CodeTooLarge,
+			expectedStatus: httpgo.StatusUnprocessableEntity,
+		},
+		{
+			name:           "timeout wins over the download error it caused",
+			err:            fmt.Errorf("%w: %w: %w", domain.ErrTimeout, ports.ErrUnreachableHost, context.DeadlineExceeded),
+			expectedCode:   handlers.CodeTimeout,
+			expectedStatus: httpgo.StatusGatewayTimeout,
+		},
+		{
+			name:           "analysis failed",
+			err:            fmt.Errorf("%w: failed to get title", domain.ErrAnalysisFailed),
+			expectedCode:   handlers.CodeAnalysisFailed,
+			expectedStatus: httpgo.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown error",
+			err:            errors.New("secret database password is wrong"),
+			expectedCode:   handlers.CodeInternalError,
+			expectedStatus: httpgo.StatusInternalServerError,
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			problem := handlers.NewProblem(tcase.err)
+
+			if problem.Code != tcase.expectedCode {
+				t.Errorf("Expected code %v, got %v", tcase.expectedCode, problem.Code)
+			}
+			if problem.Status != tcase.expectedStatus {
+				t.Errorf("Expected status %v, got %v", tcase.expectedStatus, problem.Status)
+			}
+			if problem.Type == "" || problem.Title == "" {
+				t.Errorf("Expected type and title to be set: %+v", problem)
+			}
+			if tcase.expectedCode == handlers.CodeInternalError && problem.Detail != "" {
+				t.Errorf("Internal errors must not leak details, got %q", problem.Detail)
+			}
+			if tcase.expectedCode != handlers.CodeInternalError && problem.Detail == "" {
+				t.Errorf("Expected a detail to be set: %+v", problem)
+			}
+			if problem.Detail != "" && (strings.Contains(tcase.err.Error(), problem.Detail) || strings.Contains(problem.Detail, "10.0.0.5")) {
+				t.Errorf("Expected the detail not to be taken from the error, got %q", problem.Detail)
+			}
+		})
+	}
+}
+
+type failingService struct {
+	err error
+}
+
+func (s failingService) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	return model.WebPageReport{}, s.err
+}
+
+func (s failingService) ValidateOptions(opts model.ReportOptions) error {
+	return nil
+}
+
+func (s failingService) GetWebPageReport(ctx context.Context, id string) (model.WebPageReport, error) {
+	return model.WebPageReport{}, s.err
+}
+
+func (s failingService) ListWebPageReports(ctx context.Context, url string) ([]model.WebPageReport, error) {
+	return nil, s.err
+}
+
+func TestCreateWebPageReport_Problem(t *testing.T) {
+	t.Parallel()
+	t.Run("should respond with problem details when the report fails", func(t *testing.T) {
+		handler := handlers.NewCreateWebPageReport(failingService{
+			err: fmt.Errorf("%w: %w", domain.ErrInvlidPage, ports.ErrUnreachableHost),
+		}, nil)
+		req := httptest.NewRequest(httpgo.MethodPost, "/reports/webpage", strings.NewReader(`{"url":"http://nowhere.invalid"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := handler.Handle(http.NewContext(echo.New().NewContext(req, rec))); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if rec.Code != httpgo.StatusBadGateway {
+			t.Errorf("Expected status 502, got %v", rec.Code)
+		}
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != "application/problem+json" {
+			t.Errorf("Expected problem content type, got %q", ct)
+		}
+		var problem handlers.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("Could not decode body: %v", err)
+		}
+		if problem.Code != handlers.CodeUnreachableHost {
+			t.Errorf("Expected code %v, got %v", handlers.CodeUnreachableHost, problem.Code)
+		}
+	})
+
+	t.Run("should respond with invalid request when the body cannot be bound", func(t *testing.T) {
+		handler := handlers.NewCreateWebPageReport(failingService{}, nil)
+		req := httptest.NewRequest(httpgo.MethodPost, "/reports/webpage", strings.NewReader(`{"url":`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := handler.Handle(http.NewContext(echo.New().NewContext(req, rec))); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if rec.Code != httpgo.StatusBadRequest {
+			t.Errorf("Expected status 400, got %v", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), handlers.CodeInvalidRequest) {
+			t.Errorf("Expected %v code in body, got %v", handlers.CodeInvalidRequest, rec.Body.String())
+		}
+	})
+}
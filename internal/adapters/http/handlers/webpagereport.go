@@ -1,37 +1,37 @@
 package handlers
 
 import (
+	"fmt"
 	httpgo "net/http"
 
 	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
 	"github.com/G-Fuchter/home24-assignment/internal/ports"
 )
 
 type PostWebPageReportRequestBody struct {
-	URL string `json:"url"`
+	URL       string           `json:"url"`
+	Partial   bool             `json:"partial"`
+	Analyzers []string         `json:"analyzers"`
+	Fetch     FetchOptionsBody `json:"fetch"`
+	// Async queues the report and responds right away with the job that generates it.
+	Async bool `json:"async"`
 }
 
-type PostWebPageReportResponseBody struct {
-	DocumentVersion   string `json:"documentVersion"`
-	Title             string `json:"title"`
-	ExternalLinkCount int    `json:"externalLinkCount"`
-	InternalLinkCount int    `json:"internalLinkCount"`
-	ContainsLogin     bool   `json:"containsLogin"`
-	HeaderOneCount    int    `json:"headerOneCount"`
-	HeaderTwoCount    int    `json:"headerTwoCount"`
-	HeaderThreeCount  int    `json:"headerThreeCount"`
-	HeaderFourCount   int    `json:"headerFourCount"`
-	HeaderFiveCount   int    `json:"headerFiveCount"`
-	HeaderSixCount    int    `json:"headerSixCount"`
+type ListWebPageReportsResponseBody struct {
+	Reports []*presenter.WebPageReportBody `json:"reports"`
 }
 
 type CreateWebPageReport struct {
 	webpageReportService ports.Service
+	jobService           ports.JobService
 }
 
-func NewCreateWebPageReport(webpageReportService ports.Service) *CreateWebPageReport {
+func NewCreateWebPageReport(webpageReportService ports.Service, jobService ports.JobService) *CreateWebPageReport {
 	return &CreateWebPageReport{
 		webpageReportService: webpageReportService,
+		jobService:           jobService,
 	}
 }
 
@@ -47,25 +47,92 @@ func (h *CreateWebPageReport) Handle(c http.Context) error {
 	var body PostWebPageReportRequestBody
 	err := c.Bind(&body)
 	if err != nil {
-		return c.NoContent(httpgo.StatusBadRequest)
+		return writeProblem(c, newInvalidRequestProblem(err.Error()))
 	}
-	model, err := h.webpageReportService.GenerateWebPageReport(body.URL)
+	fetch, err := body.Fetch.options()
 	if err != nil {
-		return c.NoContent(httpgo.StatusInternalServerError)
+		return writeProblem(c, newInvalidRequestProblem(err.Error()))
 	}
-	resBody := &PostWebPageReportResponseBody{
-		DocumentVersion:   model.DocumentVersion,
-		Title:             model.Title,
-		ExternalLinkCount: model.ExternalLinkCount,
-		InternalLinkCount: model.InternalLinkCount,
-		ContainsLogin:     model.ContainsLogin,
-		HeaderOneCount:    model.HeaderOneCount,
-		HeaderTwoCount:    model.HeaderTwoCount,
-		HeaderThreeCount:  model.HeaderThreeCount,
-		HeaderFourCount:   model.HeaderFourCount,
-		HeaderFiveCount:   model.HeaderFiveCount,
-		HeaderSixCount:    model.HeaderSixCount,
+	opts := model.ReportOptions{
+		Partial:   body.Partial,
+		Analyzers: body.Analyzers,
+		Fetch:     fetch,
 	}
-	c.JSON(httpgo.StatusCreated, resBody)
+	if body.Async {
+		return h.submit(c, body.URL, opts)
+	}
+	report, err := h.webpageReportService.GenerateWebPageReport(c.Request().Context(), body.URL, opts)
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Response().Header().Set("Location", fmt.Sprintf("/reports/webpage/%v", report.ID))
+	c.JSON(httpgo.StatusCreated, presenter.NewWebPageReportBody(report))
 	return nil
 }
+
+func (h *CreateWebPageReport) submit(c http.Context, location string, opts model.ReportOptions) error {
+	job, err := h.jobService.SubmitWebPageReport(c.Request().Context(), location, opts)
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Response().Header().Set("Location", fmt.Sprintf("/jobs/%v", job.ID))
+	return c.JSON(httpgo.StatusAccepted, NewReportJobResponseBody(job))
+}
+
+type GetWebPageReport struct {
+	webpageReportService ports.Service
+}
+
+func NewGetWebPageReport(webpageReportService ports.Service) *GetWebPageReport {
+	return &GetWebPageReport{
+		webpageReportService: webpageReportService,
+	}
+}
+
+func (h *GetWebPageReport) GetMethod() http.Method {
+	return http.Get
+}
+
+func (h *GetWebPageReport) GetEndpoint() string {
+	return "/reports/webpage/:id"
+}
+
+func (h *GetWebPageReport) Handle(c http.Context) error {
+	report, err := h.webpageReportService.GetWebPageReport(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(httpgo.StatusOK, presenter.NewWebPageReportBody(report))
+}
+
+type ListWebPageReports struct {
+	webpageReportService ports.Service
+}
+
+func NewListWebPageReports(webpageReportService ports.Service) *ListWebPageReports {
+	return &ListWebPageReports{
+		webpageReportService: webpageReportService,
+	}
+}
+
+func (h *ListWebPageReports) GetMethod() http.Method {
+	return http.Get
+}
+
+func (h *ListWebPageReports) GetEndpoint() string {
+	return "/reports/webpage"
+}
+
+func (h *ListWebPageReports) Handle(c http.Context) error {
+	reports, err := h.webpageReportService.ListWebPageReports(c.Request().Context(), c.QueryParam("url"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	resBody := &ListWebPageReportsResponseBody{
+		Reports: []*presenter.WebPageReportBody{},
+	}
+	for _, report := range reports {
+		resBody.Reports = append(resBody.Reports, presenter.NewWebPageReportBody(report))
+	}
+	return c.JSON(httpgo.StatusOK, resBody)
+}
@@ -0,0 +1,191 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	httpgo "net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/http/handlers"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/presenter"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/repository"
+	"github.com/G-Fuchter/home24-assignment/internal/application"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/labstack/echo/v4"
+)
+
+// titleGenerator titles every report after its URL. The "unknown" analyzer is
+// the only one that does not exist.
+type titleGenerator struct{}
+
+func (g titleGenerator) ValidateOptions(opts model.ReportOptions) error {
+	if slices.Contains(opts.Analyzers, "unknown") {
+		return domain.ErrUnknownAnalyzer
+	}
+	return nil
+}
+
+func (g titleGenerator) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	if err := domain.ValidateLocation(location); err != nil {
+		return model.WebPageReport{}, err
+	}
+	return model.WebPageReport{URL: location, Title: "Title of " + location}, nil
+}
+
+func TestWebPageReportHandlers(t *testing.T) {
+	t.Parallel()
+	e := echo.New()
+	service := application.NewService(titleGenerator{}, repository.NewMemoryReportRepository(repository.MemoryConfig{}))
+	jobs := application.NewJobQueue(service, application.JobQueueConfig{})
+	defer jobs.Close()
+	srv := http.NewServer(e, http.Config{})
+	err := srv.AddHandlers([]http.Handler{
+		handlers.NewCreateWebPageReport(service, jobs),
+		handlers.NewGetWebPageReport(service),
+		handlers.NewGetReportJob(jobs),
+		handlers.NewCreateWebPageReportBatch(application.NewBatchGenerator(service, application.BatchConfig{MaxSize: 2})),
+		handlers.NewListWebPageReports(service),
+	})
+	if err != nil {
+		t.Fatalf("Failed to add handlers: %v", err)
+	}
+
+	do := func(method string, target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	created := do(httpgo.MethodPost, "/reports/webpage", `{"url":"http://localhost"}`)
+	do(httpgo.MethodPost, "/reports/webpage", `{"url":"http://home24.de"}`)
+
+	if created.Code != httpgo.StatusCreated {
+		t.Fatalf("Expected status 201, got %v", created.Code)
+	}
+	var report presenter.WebPageReportBody
+	if err := json.Unmarshal(created.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Could not decode body: %v", err)
+	}
+	if report.ID == "" || report.CreatedAt == "" {
+		t.Fatalf("Expected ID and creation time in body, got %+v", report)
+	}
+	if location := created.Header().Get("Location"); location != "/reports/webpage/"+report.ID {
+		t.Fatalf("Expected Location header of the report, got %q", location)
+	}
+
+	t.Run("should return stored report by ID", func(t *testing.T) {
+		rec := do(httpgo.MethodGet, "/reports/webpage/"+report.ID, "")
+
+		if rec.Code != httpgo.StatusOK {
+			t.Fatalf("Expected status 200, got %v", rec.Code)
+		}
+		var found presenter.WebPageReportBody
+		json.Unmarshal(rec.Body.Bytes(), &found)
+		if found.ID != report.ID || found.Title != "Title of http://localhost" {
+			t.Fatalf("Unexpected report: %+v", found)
+		}
+	})
+
+	t.Run("should return not found for unknown IDs", func(t *testing.T) {
+		rec := do(httpgo.MethodGet, "/reports/webpage/unknown", "")
+
+		if rec.Code != httpgo.StatusNotFound {
+			t.Fatalf("Expected status 404, got %v", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), handlers.CodeReportNotFound) {
+			t.Fatalf("Expected %v code in body, got %v", handlers.CodeReportNotFound, rec.Body.String())
+		}
+	})
+
+	t.Run("should list reports by URL", func(t *testing.T) {
+		rec := do(httpgo.MethodGet, "/reports/webpage?url=http://localhost", "")
+
+		if rec.Code != httpgo.StatusOK {
+			t.Fatalf("Expected status 200, got %v", rec.Code)
+		}
+		var list handlers.ListWebPageReportsResponseBody
+		json.Unmarshal(rec.Body.Bytes(), &list)
+		if len(list.Reports) != 1 || list.Reports[0].ID != report.ID {
+			t.Fatalf("Expected only the report of http://localhost, got %+v", list.Reports)
+		}
+	})
+	t.Run("should generate report in the background when async", func(t *testing.T) {
+		rec := do(httpgo.MethodPost, "/reports/webpage", `{"url":"http://localhost/async","async":true}`)
+
+		if rec.Code != httpgo.StatusAccepted {
+			t.Fatalf("Expected status 202, got %v", rec.Code)
+		}
+		var job handlers.ReportJobResponseBody
+		json.Unmarshal(rec.Body.Bytes(), &job)
+		if location := rec.Header().Get("Location"); location != "/jobs/"+job.ID {
+			t.Fatalf("Expected Location header of the job, got %q", location)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for job.State != string(model.JobStateSucceeded) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Job did not succeed in time: %+v", job)
+			}
+			time.Sleep(10 * time.Millisecond)
+			rec = do(httpgo.MethodGet, "/jobs/"+job.ID, "")
+			if rec.Code != httpgo.StatusOK {
+				t.Fatalf("Expected status 200, got %v", rec.Code)
+			}
+			json.Unmarshal(rec.Body.Bytes(), &job)
+		}
+
+		if job.Report == nil || job.Report.Title != "Title of http://localhost/async" || job.Report.ID == "" {
+			t.Fatalf("Expected the stored report in the job, got %+v", job.Report)
+		}
+	})
+
+	t.Run("should reject unknown analyzers before queueing", func(t *testing.T) {
+		rec := do(httpgo.MethodPost, "/reports/webpage", `{"url":"http://localhost/async","async":true,"analyzers":["unknown"]}`)
+
+		if rec.Code != httpgo.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %v", rec.Code)
+		}
+	})
+
+	t.Run("should return not found for unknown jobs", func(t *testing.T) {
+		rec := do(httpgo.MethodGet, "/jobs/unknown", "")
+
+		if rec.Code != httpgo.StatusNotFound {
+			t.Fatalf("Expected status 404, got %v", rec.Code)
+		}
+	})
+	t.Run("should return a result per URL of a batch", func(t *testing.T) {
+		rec := do(httpgo.MethodPost, "/reports/webpage/batch", `{"urls":["http://localhost/a","not a url"]}`)
+
+		if rec.Code != httpgo.StatusOK {
+			t.Fatalf("Expected status 200, got %v", rec.Code)
+		}
+		var body handlers.PostWebPageReportBatchResponseBody
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if len(body.Results) != 2 {
+			t.Fatalf("Expected 2 results, got %+v", body.Results)
+		}
+		if body.Results[0].Report == nil || body.Results[0].Report.ID == "" {
+			t.Errorf("Expected the stored report of the first URL, got %+v", body.Results[0])
+		}
+		if body.Results[1].Error == nil || body.Results[1].Error.Code != handlers.CodeInvalidURL {
+			t.Errorf("Expected invalid_url for the second URL, got %+v", body.Results[1])
+		}
+	})
+
+	t.Run("should reject batches that are too large", func(t *testing.T) {
+		rec := do(httpgo.MethodPost, "/reports/webpage/batch", `{"urls":["http://localhost/a","http://localhost/b","http://localhost/c"]}`)
+
+		if rec.Code != httpgo.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %v", rec.Code)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/labstack/echo/v4"
@@ -30,30 +31,27 @@ func (s *Server) AddHandlers(h []Handler) error {
 		case Get:
 			s.srv.GET(
 				value.GetEndpoint(),
-				func(c echo.Context) error { return value.Handle(c) },
+				func(c echo.Context) error { return value.Handle(NewContext(c)) },
 			)
-			return nil
 		case Post:
 			s.srv.POST(
 				value.GetEndpoint(),
-				func(c echo.Context) error { return value.Handle(c) },
+				func(c echo.Context) error { return value.Handle(NewContext(c)) },
 			)
-			return nil
 		case Put:
 			s.srv.PUT(
 				value.GetEndpoint(),
-				func(c echo.Context) error { return value.Handle(c) },
+				func(c echo.Context) error { return value.Handle(NewContext(c)) },
 			)
-			return nil
 		case Delete:
 			s.srv.DELETE(
 				value.GetEndpoint(),
-				func(c echo.Context) error { return value.Handle(c) },
+				func(c echo.Context) error { return value.Handle(NewContext(c)) },
 			)
 		case Patch:
 			s.srv.PATCH(
 				value.GetEndpoint(),
-				func(c echo.Context) error { return value.Handle(c) },
+				func(c echo.Context) error { return value.Handle(NewContext(c)) },
 			)
 		default:
 			return fmt.Errorf(
@@ -66,6 +64,22 @@ func (s *Server) AddHandlers(h []Handler) error {
 	return nil
 }
 
+// echoContext adapts an echo context to Context, so that handlers do not depend
+// on echo.
+type echoContext struct {
+	echo.Context
+}
+
+// NewContext returns the Context of the request c is handling.
+func NewContext(c echo.Context) Context {
+	return echoContext{c}
+}
+
+// Response implements the Context interface.
+func (c echoContext) Response() Response {
+	return c.Context.Response()
+}
+
 func (s *Server) EnableCORS() {
 	s.srv.Use(middleware.CORS())
 }
@@ -79,3 +93,9 @@ func (s *Server) Start() error {
 	hostname := s.cfg.Hostname
 	return s.srv.Start(fmt.Sprintf("%v:%v", hostname, port))
 }
+
+// Shutdown stops accepting requests and waits for the ones in flight to
+// finish, or for ctx to be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
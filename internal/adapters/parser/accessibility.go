@@ -0,0 +1,310 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Rules checked by the accessibility analyzer.
+const (
+	RuleHeadingOrder  = "heading_order"
+	RuleLabel         = "label"
+	RuleButtonName    = "button_name"
+	RuleLinkName      = "link_name"
+	RuleHTMLLang      = "html_lang"
+	RuleDuplicateID   = "duplicate_id"
+	RuleARIARole      = "aria_role"
+	RuleARIAAttribute = "aria_attribute"
+	RuleTableHeaders  = "table_headers"
+)
+
+// ariaRoles are the roles defined by WAI-ARIA 1.2. Roles of the DPUB and
+// graphics modules are recognized by their prefix.
+var ariaRoles = setOf(
+	"alert", "alertdialog", "application", "article", "banner", "blockquote", "button", "caption", "cell",
+	"checkbox", "code", "columnheader", "combobox", "complementary", "contentinfo", "definition", "deletion",
+	"dialog", "directory", "document", "emphasis", "feed", "figure", "form", "generic", "grid", "gridcell",
+	"group", "heading", "img", "insertion", "link", "list", "listbox", "listitem", "log", "main", "marquee",
+	"math", "menu", "menubar", "menuitem", "menuitemcheckbox", "menuitemradio", "meter", "navigation", "none",
+	"note", "option", "paragraph", "presentation", "progressbar", "radio", "radiogroup", "region", "row",
+	"rowgroup", "rowheader", "scrollbar", "search", "searchbox", "separator", "slider", "spinbutton", "status",
+	"strong", "subscript", "superscript", "switch", "tab", "table", "tablist", "tabpanel", "term", "textbox",
+	"time", "timer", "toolbar", "tooltip", "tree", "treegrid", "treeitem",
+)
+
+// ariaAttributes are the states and properties defined by WAI-ARIA 1.2.
+var ariaAttributes = setOf(
+	"aria-activedescendant", "aria-atomic", "aria-autocomplete", "aria-braillelabel", "aria-brailleroledescription",
+	"aria-busy", "aria-checked", "aria-colcount", "aria-colindex", "aria-colindextext", "aria-colspan",
+	"aria-controls", "aria-current", "aria-describedby", "aria-description", "aria-details", "aria-disabled",
+	"aria-dropeffect", "aria-errormessage", "aria-expanded", "aria-flowto", "aria-grabbed", "aria-haspopup",
+	"aria-hidden", "aria-invalid", "aria-keyshortcuts", "aria-label", "aria-labelledby", "aria-level", "aria-live",
+	"aria-modal", "aria-multiline", "aria-multiselectable", "aria-orientation", "aria-owns", "aria-placeholder",
+	"aria-posinset", "aria-pressed", "aria-readonly", "aria-relevant", "aria-required", "aria-roledescription",
+	"aria-rowcount", "aria-rowindex", "aria-rowindextext", "aria-rowspan", "aria-selected", "aria-setsize",
+	"aria-sort", "aria-valuemax", "aria-valuemin", "aria-valuenow", "aria-valuetext",
+)
+
+// unlabeledInputTypes are the input types that do not need a label, as they are
+// either not shown or labeled by their value.
+var unlabeledInputTypes = setOf("hidden", "submit", "button", "reset", "image")
+
+// buttonInputTypes are the input types that are buttons.
+var buttonInputTypes = setOf("submit", "button", "reset", "image")
+
+// AccessibilityResult lists the accessibility problems of a page, in document
+// order, and how many were found of every rule.
+type AccessibilityResult struct {
+	Findings []AccessibilityFinding `json:"findings"`
+	Counts   map[string]int         `json:"counts"`
+}
+
+// Apply implements the Result interface.
+func (r AccessibilityResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("accessibility", r)
+}
+
+// AccessibilityFinding is a problem with a single element, which XPath locates.
+type AccessibilityFinding struct {
+	Rule    string `json:"rule"`
+	XPath   string `json:"xpath"`
+	Message string `json:"message"`
+}
+
+// AccessibilityAnalyzer runs basic automated WCAG checks on the document.
+type AccessibilityAnalyzer struct{}
+
+func NewAccessibilityAnalyzer() *AccessibilityAnalyzer {
+	return &AccessibilityAnalyzer{}
+}
+
+// Name implements the Analyzer interface.
+func (a *AccessibilityAnalyzer) Name() string {
+	return "accessibility"
+}
+
+// Analyze implements the Analyzer interface.
+func (a *AccessibilityAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	root := document.Root()
+	if root == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	c := newAccessibilityCheck(root)
+	c.walk(root)
+	return AccessibilityResult{Findings: c.findings, Counts: c.counts}, nil
+}
+
+// accessibilityCheck holds the state of the checks of a single document.
+type accessibilityCheck struct {
+	// ids are the first elements with every id, and labeled the ids of the
+	// elements a <label for> points to.
+	ids     map[string]*html.Node
+	labeled map[string]bool
+	// heading is the level of the last heading, or 0 before the first one.
+	heading  int
+	findings []AccessibilityFinding
+	counts   map[string]int
+}
+
+func newAccessibilityCheck(root *html.Node) *accessibilityCheck {
+	c := &accessibilityCheck{
+		ids:      map[string]*html.Node{},
+		labeled:  map[string]bool{},
+		findings: []AccessibilityFinding{},
+		counts:   map[string]int{},
+	}
+	var index func(n *html.Node)
+	index = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := htmlquery.SelectAttr(n, "id"); id != "" && c.ids[id] == nil {
+				c.ids[id] = n
+			}
+			if n.Data == "label" {
+				if id := htmlquery.SelectAttr(n, "for"); id != "" {
+					c.labeled[id] = true
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			index(child)
+		}
+	}
+	index(root)
+	return c
+}
+
+func (c *accessibilityCheck) report(n *html.Node, rule string, format string, args ...any) {
+	c.findings = append(c.findings, AccessibilityFinding{rule, xpathOf(n), fmt.Sprintf(format, args...)})
+	c.counts[rule]++
+}
+
+func (c *accessibilityCheck) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		c.checkElement(n)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+}
+
+func (c *accessibilityCheck) checkElement(n *html.Node) {
+	if id := htmlquery.SelectAttr(n, "id"); id != "" {
+		if c.ids[id] != n {
+			c.report(n, RuleDuplicateID, "id %q is used by another element", id)
+		}
+	}
+	for _, role := range strings.Fields(htmlquery.SelectAttr(n, "role")) {
+		role = strings.ToLower(role)
+		if !ariaRoles[role] && !strings.HasPrefix(role, "doc-") && !strings.HasPrefix(role, "graphics-") {
+			c.report(n, RuleARIARole, "role %q is not a valid ARIA role", role)
+		}
+	}
+	for _, a := range n.Attr {
+		if strings.HasPrefix(a.Key, "aria-") && !ariaAttributes[a.Key] {
+			c.report(n, RuleARIAAttribute, "%v is not a valid ARIA attribute", a.Key)
+		}
+	}
+
+	switch n.Data {
+	case "html":
+		if strings.TrimSpace(htmlquery.SelectAttr(n, "lang")) == "" {
+			c.report(n, RuleHTMLLang, "the <html> element has no lang attribute")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Data[1:])
+		if c.heading > 0 && level > c.heading+1 {
+			c.report(n, RuleHeadingOrder, "heading level %d follows level %d", level, c.heading)
+		}
+		c.heading = level
+	case "input":
+		inputType := strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "type")))
+		if buttonInputTypes[inputType] {
+			if c.inputButtonName(n, inputType) == "" {
+				c.report(n, RuleButtonName, "the button has no accessible name")
+			}
+		} else if !unlabeledInputTypes[inputType] && !c.isLabeled(n) {
+			c.report(n, RuleLabel, "the form field has no label")
+		}
+	case "select", "textarea":
+		if !c.isLabeled(n) {
+			c.report(n, RuleLabel, "the form field has no label")
+		}
+	case "button":
+		if c.accessibleName(n) == "" {
+			c.report(n, RuleButtonName, "the button has no accessible name")
+		}
+	case "a":
+		if hasAttr(n, "href") && c.accessibleName(n) == "" {
+			c.report(n, RuleLinkName, "the link has no accessible name")
+		}
+	case "table":
+		role := strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "role")))
+		if role != "presentation" && role != "none" && htmlquery.FindOne(n, ".//th") == nil {
+			c.report(n, RuleTableHeaders, "the table has no header cells")
+		}
+	}
+}
+
+// isLabeled tells whether a form field has a label, either a <label> element or
+// an ARIA one.
+func (c *accessibilityCheck) isLabeled(n *html.Node) bool {
+	if id := htmlquery.SelectAttr(n, "id"); id != "" && c.labeled[id] {
+		return true
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "label" {
+			return true
+		}
+	}
+	return c.ariaName(n) != "" || strings.TrimSpace(htmlquery.SelectAttr(n, "title")) != ""
+}
+
+func (c *accessibilityCheck) inputButtonName(n *html.Node, inputType string) string {
+	if name := c.ariaName(n); name != "" {
+		return name
+	}
+	names := []string{htmlquery.SelectAttr(n, "value"), htmlquery.SelectAttr(n, "title")}
+	if inputType == "image" {
+		names = append(names, htmlquery.SelectAttr(n, "alt"))
+	}
+	// Submit and reset buttons are named by the browser when they have no value.
+	if inputType == "submit" || inputType == "reset" {
+		names = append(names, inputType)
+	}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// accessibleName returns the name assistive technologies announce for a button
+// or a link: its ARIA label, its text or the alt text of its images, or its title.
+func (c *accessibilityCheck) accessibleName(n *html.Node) string {
+	if name := c.ariaName(n); name != "" {
+		return name
+	}
+	if text := strings.TrimSpace(htmlquery.InnerText(n)); text != "" {
+		return text
+	}
+	for _, img := range htmlquery.Find(n, ".//img[@alt]") {
+		if alt := strings.TrimSpace(htmlquery.SelectAttr(img, "alt")); alt != "" {
+			return alt
+		}
+	}
+	return strings.TrimSpace(htmlquery.SelectAttr(n, "title"))
+}
+
+// ariaName returns the name given by aria-labelledby or aria-label.
+func (c *accessibilityCheck) ariaName(n *html.Node) string {
+	var names []string
+	for _, id := range strings.Fields(htmlquery.SelectAttr(n, "aria-labelledby")) {
+		if label := c.ids[id]; label != nil {
+			names = append(names, strings.TrimSpace(htmlquery.InnerText(label)))
+		}
+	}
+	if name := strings.TrimSpace(strings.Join(names, " ")); name != "" {
+		return name
+	}
+	return strings.TrimSpace(htmlquery.SelectAttr(n, "aria-label"))
+}
+
+// xpathOf returns the absolute XPath of an element, such as
+// /html/body/div[2]/a. Positions are only given among siblings with the same
+// name.
+func xpathOf(n *html.Node) string {
+	var steps []string
+	for ; n != nil && n.Type == html.ElementNode && n.Parent != nil; n = n.Parent {
+		position, count := 0, 0
+		for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+			if s.Type == html.ElementNode && s.Data == n.Data {
+				count++
+				if s == n {
+					position = count
+				}
+			}
+		}
+		step := n.Data
+		if count > 1 {
+			step = fmt.Sprintf("%v[%d]", n.Data, position)
+		}
+		steps = append([]string{step}, steps...)
+	}
+	return "/" + strings.Join(steps, "/")
+}
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+)
+
+func TestAccessibilityAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		html             string
+		expectedFindings []parser.AccessibilityFinding
+	}{
+		{
+			name: "should not report an accessible page",
+			html: `<html lang="de"><body>
+				<h1>Sofas</h1><h2>Ecksofas</h2><h3>Anton</h3><h2>Schlafsofas</h2>
+				<form>
+					<label for="search">Suche</label><input id="search" type="search">
+					<label>Menge <select><option>1</option></select></label>
+					<textarea aria-label="Kommentar"></textarea>
+					<input type="hidden" name="token">
+					<input type="submit">
+					<button><img src="/cart.svg" alt="Warenkorb"></button>
+				</form>
+				<a href="/" aria-label="Startseite"><svg></svg></a>
+				<span id="more">Mehr</span><a href="/more" aria-labelledby="more"></a>
+				<nav role="navigation" aria-expanded="false"></nav>
+				<table><tr><th>Preis</th></tr><tr><td>499 €</td></tr></table>
+				<table role="presentation"><tr><td>Layout</td></tr></table>
+			</body></html>`,
+			expectedFindings: []parser.AccessibilityFinding{},
+		},
+		{
+			name: "should report every rule with the XPath of the element",
+			html: `<html><body>
+				<h1>Sofas</h1><h3>Anton</h3>
+				<div id="product">
+					<input type="text" name="email">
+					<button></button>
+					<input type="button">
+				</div>
+				<div id="product">
+					<a href="/cart"><img src="/cart.svg"></a>
+					<span role="buton" aria-lable="Menu"></span>
+					<table><tr><td>499 €</td></tr></table>
+				</div>
+			</body></html>`,
+			expectedFindings: []parser.AccessibilityFinding{
+				{Rule: parser.RuleHTMLLang, XPath: "/html", Message: "the <html> element has no lang attribute"},
+				{Rule: parser.RuleHeadingOrder, XPath: "/html/body/h3", Message: "heading level 3 follows level 1"},
+				{Rule: parser.RuleLabel, XPath: "/html/body/div[1]/input[1]", Message: "the form field has no label"},
+				{Rule: parser.RuleButtonName, XPath: "/html/body/div[1]/button", Message: "the button has no accessible name"},
+				{Rule: parser.RuleButtonName, XPath: "/html/body/div[1]/input[2]", Message: "the button has no accessible name"},
+				{Rule: parser.RuleDuplicateID, XPath: "/html/body/div[2]", Message: `id "product" is used by another element`},
+				{Rule: parser.RuleLinkName, XPath: "/html/body/div[2]/a", Message: "the link has no accessible name"},
+				{Rule: parser.RuleARIARole, XPath: "/html/body/div[2]/span", Message: `role "buton" is not a valid ARIA role`},
+				{Rule: parser.RuleARIAAttribute, XPath: "/html/body/div[2]/span", Message: "aria-lable is not a valid ARIA attribute"},
+				{Rule: parser.RuleTableHeaders, XPath: "/html/body/div[2]/table", Message: "the table has no header cells"},
+			},
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString(tcase.html, "https://www.home24.de/sofas")
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			result, err := parser.NewAccessibilityAnalyzer().Analyze(context.Background(), doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			accessibility := result.(parser.AccessibilityResult)
+			if fmt.Sprint(accessibility.Findings) != fmt.Sprint(tcase.expectedFindings) {
+				t.Errorf("Expected findings %v, got %v", tcase.expectedFindings, accessibility.Findings)
+			}
+			total := 0
+			for _, count := range accessibility.Counts {
+				total += count
+			}
+			if total != len(accessibility.Findings) {
+				t.Errorf("Expected counts to add up to %v, got %v", len(accessibility.Findings), accessibility.Counts)
+			}
+		})
+	}
+}
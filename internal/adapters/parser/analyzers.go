@@ -0,0 +1,39 @@
+package parser
+
+import "github.com/G-Fuchter/home24-assignment/internal/ports"
+
+// AnalyzersConfig configures the analyzers that need it.
+type AnalyzersConfig struct {
+	StructuredData StructuredDataConfig
+	Images         ImagesConfig
+}
+
+// BuiltInAnalyzers returns the analyzers that fill the built-in fields of the
+// report, in the order of the fields. Links are checked with fetcher.
+func BuiltInAnalyzers(fetcher ports.Fetcher) []ports.Analyzer {
+	analyzers := []ports.Analyzer{
+		NewDocumentVersionAnalyzer(),
+		NewTitleAnalyzer(),
+		NewExternalLinkCountAnalyzer(),
+		NewInternalLinkCountAnalyzer(),
+		NewContainsLoginAnalyzer(),
+	}
+	for level := 1; level <= len(headingFields); level++ {
+		analyzers = append(analyzers, NewHeadingCountAnalyzer(level))
+	}
+	checker := NewLinkChecker(fetcher, defaultLinkCheckConcurrency, defaultLinkCheckTimeout)
+	return append(analyzers, NewInaccessibleLinksAnalyzer(checker))
+}
+
+// Analyzers returns the optional analyzers provided by this package, in the order they
+// are meant to be registered. Every entry point registers the same ones.
+func Analyzers(cfg AnalyzersConfig) []ports.Analyzer {
+	return []ports.Analyzer{
+		NewSecurityHeadersAnalyzer(),
+		NewSEOAnalyzer(),
+		NewSocialAnalyzer(),
+		NewStructuredDataAnalyzer(cfg.StructuredData),
+		NewImagesAnalyzer(cfg.Images),
+		NewAccessibilityAnalyzer(),
+	}
+}
@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html/charset"
+)
+
+var utf8BOM = []byte("\uFEFF")
+
+// decode transcodes a document to UTF-8 and returns the name of the encoding it
+// was in. The encoding is taken from the byte order mark, then from contentType
+// and then from the <meta> tags of the document, as browsers do. Documents that
+// declare nothing are assumed to be UTF-8 when they are valid UTF-8, and
+// windows-1252 otherwise.
+func decode(content []byte, contentType string) ([]byte, string, error) {
+	encoding, name, _ := charset.DetermineEncoding(content, contentType)
+	decoded, err := encoding.NewDecoder().Bytes(content)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.TrimPrefix(decoded, utf8BOM), name, nil
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+var regexDocumentVersion = regexp.MustCompile(`[Hh][Tt][Mm][Ll] ([0-9](\.[0-9][0-9]*)*)`)
+
+// headingFields are the fields of the report that hold the number of headings
+// of every level, starting with <h1>.
+var headingFields = []string{"headerOneCount", "headerTwoCount", "headerThreeCount", "headerFourCount", "headerFiveCount", "headerSixCount"}
+
+// FieldAnalyzer fills one of the built-in fields of the report, which it is
+// named after.
+type FieldAnalyzer struct {
+	field   string
+	stage   model.ReportStage
+	analyze func(ctx context.Context, document ports.Document) (ports.Result, error)
+}
+
+// Name implements the Analyzer interface.
+func (a *FieldAnalyzer) Name() string {
+	return a.field
+}
+
+// Stage implements the StagedAnalyzer interface.
+func (a *FieldAnalyzer) Stage() model.ReportStage {
+	return a.stage
+}
+
+// Analyze implements the Analyzer interface.
+func (a *FieldAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	if document.Root() == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	return a.analyze(ctx, document)
+}
+
+// DocumentVersion is the HTML version a document declares, such as "5".
+type DocumentVersion string
+
+// Apply implements the Result interface.
+func (v DocumentVersion) Apply(report *model.WebPageReport) {
+	report.DocumentVersion = string(v)
+}
+
+func NewDocumentVersionAnalyzer() *FieldAnalyzer {
+	return &FieldAnalyzer{"documentVersion", model.StageDocument, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		doctypeNode := document.Root().FirstChild
+		if doctypeNode == nil || doctypeNode.Type != html.DoctypeNode {
+			return nil, ErrNoVersionFound
+		}
+		isVersionFive := len(doctypeNode.Attr) == 0
+		if isVersionFive {
+			return DocumentVersion("5"), nil
+		}
+		rxResult := regexDocumentVersion.FindStringSubmatch(doctypeNode.Attr[0].Val)
+		if rxResult == nil {
+			return nil, ErrNoVersionFound
+		}
+		return DocumentVersion(rxResult[1]), nil
+	}}
+}
+
+// Title is the text of the <title> element of a document.
+type Title string
+
+// Apply implements the Result interface.
+func (t Title) Apply(report *model.WebPageReport) {
+	report.Title = string(t)
+}
+
+func NewTitleAnalyzer() *FieldAnalyzer {
+	return &FieldAnalyzer{"title", model.StageTitle, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		title, err := documentTitle(document.Root())
+		if err != nil {
+			return nil, err
+		}
+		return Title(title), nil
+	}}
+}
+
+// documentTitle returns the text of the <title> element of doc, or an error
+// wrapping ErrElementNotFound when it has none.
+func documentTitle(doc *html.Node) (string, error) {
+	title, err := htmlquery.Query(doc, "//title")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	if title == nil {
+		return "", ErrElementNotFound
+	}
+	if title.FirstChild == nil {
+		return "", nil
+	}
+	return title.FirstChild.Data, nil
+}
+
+// ExternalLinkCount is the number of links to other hosts.
+type ExternalLinkCount int
+
+// Apply implements the Result interface.
+func (c ExternalLinkCount) Apply(report *model.WebPageReport) {
+	report.ExternalLinkCount = int(c)
+}
+
+// InternalLinkCount is the number of links to the host of the document.
+type InternalLinkCount int
+
+// Apply implements the Result interface.
+func (c InternalLinkCount) Apply(report *model.WebPageReport) {
+	report.InternalLinkCount = int(c)
+}
+
+func NewExternalLinkCountAnalyzer() *FieldAnalyzer {
+	return &FieldAnalyzer{"externalLinkCount", model.StageLinks, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		count, err := countLinks(document, false)
+		return ExternalLinkCount(count), err
+	}}
+}
+
+func NewInternalLinkCountAnalyzer() *FieldAnalyzer {
+	return &FieldAnalyzer{"internalLinkCount", model.StageLinks, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		count, err := countLinks(document, true)
+		return InternalLinkCount(count), err
+	}}
+}
+
+// countLinks returns the number of links of document that are internal, or
+// external when internal is false.
+func countLinks(document ports.Document, internal bool) (int, error) {
+	count := 0
+	for _, link := range document.Links() {
+		isInternal, err := isLinkURLInternal(document.URL(), link)
+		if err != nil {
+			return 0, err
+		}
+		if isInternal == internal {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ContainsLogin tells whether a document has a login form.
+type ContainsLogin bool
+
+// Apply implements the Result interface.
+func (c ContainsLogin) Apply(report *model.WebPageReport) {
+	report.ContainsLogin = bool(c)
+}
+
+func NewContainsLoginAnalyzer() *FieldAnalyzer {
+	return &FieldAnalyzer{"containsLogin", model.StageDocument, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		forms, err := htmlquery.QueryAll(document.Root(), "//form")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range forms {
+			inputs, err := htmlquery.QueryAll(f, "//input")
+			hasUserInput, hasPasswordInput := false, false
+			if err != nil {
+				return nil, fmt.Errorf("could not retrieve login: %w", err)
+			}
+			for _, i := range inputs {
+				for _, attr := range i.Attr {
+					if attr.Key == "type" {
+						if attr.Val == "text" || attr.Val == "email" {
+							hasUserInput = true
+						} else if attr.Val == "password" {
+							hasPasswordInput = true
+						}
+					}
+				}
+			}
+			if hasUserInput && hasPasswordInput {
+				return ContainsLogin(true), nil
+			}
+		}
+		return ContainsLogin(false), nil
+	}}
+}
+
+// HeadingCount is the number of headings of a level, from 1 to 6.
+type HeadingCount struct {
+	Level int
+	Count int
+}
+
+// Apply implements the Result interface.
+func (c HeadingCount) Apply(report *model.WebPageReport) {
+	counts := []*int{
+		&report.HeaderOneCount, &report.HeaderTwoCount, &report.HeaderThreeCount,
+		&report.HeaderFourCount, &report.HeaderFiveCount, &report.HeaderSixCount,
+	}
+	*counts[c.Level-1] = c.Count
+}
+
+// NewHeadingCountAnalyzer returns the analyzer that counts the headings of
+// level, which must be between 1 and 6.
+func NewHeadingCountAnalyzer(level int) *FieldAnalyzer {
+	return &FieldAnalyzer{headingFields[level-1], model.StageHeadings, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		count, err := countElements(document.Root(), fmt.Sprintf("//h%d", level))
+		if err != nil {
+			return nil, err
+		}
+		return HeadingCount{Level: level, Count: count}, nil
+	}}
+}
+
+// InaccessibleLinks are the links of a document that could not be reached.
+type InaccessibleLinks []model.InaccessibleLink
+
+// Apply implements the Result interface.
+func (l InaccessibleLinks) Apply(report *model.WebPageReport) {
+	report.InaccessibleLinks = l
+	report.InaccessibleLinkCount = len(l)
+}
+
+// NewInaccessibleLinksAnalyzer returns the analyzer that checks the links of
+// the document with checker, using the options the document was downloaded with.
+func NewInaccessibleLinksAnalyzer(checker *LinkChecker) *FieldAnalyzer {
+	return &FieldAnalyzer{"inaccessibleLinks", model.StageLinkChecks, func(ctx context.Context, document ports.Document) (ports.Result, error) {
+		base, err := url.Parse(document.URL())
+		if err != nil {
+			return nil, fmt.Errorf("the website's URL is not valid: %w", err)
+		}
+		return InaccessibleLinks(checker.Check(ctx, base, resolveLinks(base, document.Links()), document.FetchOptions())), nil
+	}}
+}
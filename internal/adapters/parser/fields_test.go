@@ -0,0 +1,660 @@
+package parser_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// analyze runs a on doc and returns its result, or the zero value of T when it
+// fails.
+func analyze[T ports.Result](a ports.Analyzer, doc ports.Document) (T, error) {
+	var zero T
+	result, err := a.Analyze(context.Background(), doc)
+	if err != nil {
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+func newInaccessibleLinksAnalyzer() *parser.FieldAnalyzer {
+	return parser.NewInaccessibleLinksAnalyzer(parser.NewLinkChecker(fetcher.NewHTTPFetcher(fetcher.Config{}), 0, 0))
+}
+
+// internalResolver resolves every host to the same private address.
+type internalResolver struct{}
+
+const internalAddr = "10.20.30.40"
+
+func (internalResolver) LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+	return []netip.Addr{netip.MustParseAddr(internalAddr)}, nil
+}
+
+// newInternalFetcher returns a fetcher whose policy forbids every host, as
+// they all resolve to internalAddr.
+func newInternalFetcher(t *testing.T) *fetcher.HTTPFetcher {
+	policy, err := fetcher.NewPolicy(fetcher.PolicyConfig{Resolver: internalResolver{}})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	return fetcher.NewHTTPFetcher(fetcher.Config{Policy: policy})
+}
+
+func TestDocumentVersionAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	// Test without loading document
+	t.Run("should return error when no document is loaded", func(t *testing.T) {
+		doc := &parser.WebPageDocument{}
+		_, err := analyze[parser.DocumentVersion](parser.NewDocumentVersionAnalyzer(), doc)
+		if err != parser.ErrDocumentNotLoaded {
+			t.Fatalf("Expected document not loaded error to be return: %v", err)
+		}
+	})
+
+	t.Run("should return error when there is no doctype", func(t *testing.T) {
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+		doc, err := prsr.FromString("<html><head><title></title></head></html>", "")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		_, err = analyze[parser.DocumentVersion](parser.NewDocumentVersionAnalyzer(), doc)
+		if err != parser.ErrNoVersionFound {
+			t.Fatalf("Expected error was not returned: %v", err)
+		}
+
+	})
+	t.Run("should return correct html version", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			html            string
+			expectedVersion parser.DocumentVersion
+		}{
+			{
+				name:            "version 5",
+				html:            "<!DOCTYPE html><html><head><title>Test</title></head></html>",
+				expectedVersion: "5",
+			},
+			{
+				name:            "version 4.01",
+				html:            "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 4.01//EN\" \"http://www.w3.org/TR/html4/strict.dtd\"><html><head><title>Test</title></head></html>",
+				expectedVersion: "4.01",
+			},
+			{
+				name:            "version 3.2",
+				html:            "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 3.2 Final//EN\"><html><head><title>Test</title></head></html>",
+				expectedVersion: "3.2",
+			},
+			{
+				name:            "version 2.0",
+				html:            "<!DOCTYPE html PUBLIC \"-//IETF//DTD HTML 2.0//EN\"><html><head><title>Test</title></head></html>",
+				expectedVersion: "2.0",
+			},
+		}
+
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+		for _, tcase := range tests {
+			t.Run(tcase.name, func(t *testing.T) {
+				doc, err := prsr.FromString(tcase.html, "")
+				if err != nil {
+					t.Fatalf("Failed to load document: %v", err)
+				}
+
+				actual, err := analyze[parser.DocumentVersion](parser.NewDocumentVersionAnalyzer(), doc)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if actual != tcase.expectedVersion {
+					t.Fatalf("Expected %v version and returned %v", tcase.expectedVersion, actual)
+				}
+			})
+		}
+	})
+}
+
+func TestTitleAnalyzer(t *testing.T) {
+	t.Parallel()
+	t.Run("should return error when document is not loaded", func(t *testing.T) {
+
+		doc := &parser.WebPageDocument{}
+
+		title, err := analyze[parser.Title](parser.NewTitleAnalyzer(), doc)
+
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+
+		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+			t.Errorf("Expected ErrDocumentNotLoaded, got %v", err)
+		}
+
+		if title != "" {
+			t.Errorf("Expected empty title, got %q", title)
+		}
+	})
+	t.Run("should return correct title", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			html          string
+			expectedTitle parser.Title
+			expectError   bool
+			errorType     error
+		}{
+			{
+				name:          "valid title",
+				html:          "<html><head><title>Test Page</title></head></html>",
+				expectedTitle: "Test Page",
+				expectError:   false,
+			},
+			{
+				name:          "empty title",
+				html:          "<html><head><title></title></head></html>",
+				expectedTitle: "",
+				expectError:   false,
+			},
+			{
+				name:        "no title element",
+				html:        "<html><head></head></html>",
+				expectError: true,
+				errorType:   parser.ErrElementNotFound,
+			},
+		}
+		for _, tcase := range tests {
+			t.Run(tcase.name, func(t *testing.T) {
+				prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+				doc, err := prsr.FromString(tcase.html, "")
+				if err != nil {
+					t.Fatalf("Failed to load document: %v", err)
+				}
+
+				title, err := analyze[parser.Title](parser.NewTitleAnalyzer(), doc)
+
+				if tcase.expectError {
+					if err == nil {
+						t.Error("Expected error but got none")
+					}
+					if tcase.errorType != nil && !errors.Is(err, tcase.errorType) {
+						t.Errorf("Expected error type %v, got %v", tcase.errorType, err)
+					}
+				} else {
+					if err != nil {
+						t.Errorf("Unexpected error: %v", err)
+					}
+					if title != tcase.expectedTitle {
+						t.Errorf("Expected title %q, got %q", tcase.expectedTitle, title)
+					}
+				}
+			})
+		}
+	})
+
+}
+
+func TestExternalLinkCountAnalyzer(t *testing.T) {
+	t.Parallel()
+	t.Run("should return error when document is not loaded", func(t *testing.T) {
+
+		doc := &parser.WebPageDocument{}
+
+		count, err := analyze[parser.ExternalLinkCount](parser.NewExternalLinkCountAnalyzer(), doc)
+
+		if err == nil {
+			t.Fatalf("Expected error but got none")
+		}
+
+		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		}
+
+		if count != 0 {
+			t.Fatalf("Expected count of 0, got %v", count)
+		}
+	})
+
+	t.Run("should return correct external link count", func(t *testing.T) {
+
+		tests := []struct {
+			name          string
+			html          string
+			expectedCount parser.ExternalLinkCount
+			pageURL       string
+		}{
+			{
+				name:          "one external link",
+				html:          `<html><body><a href="https://www.home24.com"></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "two external links",
+				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="https://www.google.com"></a></div></body></html>`,
+				expectedCount: 2,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "ignore internal links",
+				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="/home"></a><a hrel="http://localhost/contact"></a></div></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "no external links",
+				html:          `<html><body><div><a href="/home"></a></div></body></html>`,
+				expectedCount: 0,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "ignore internal links that contain hostname",
+				html:          `<html><body><div><a href="http://localhost/home"></a></div></body></html>`,
+				expectedCount: 0,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "sub domains count as externals",
+				html:          `<html><body><div><a href="http://support.home24.de"></a></div></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://home24.de",
+			},
+			{
+				name:          "absolute links are external when the page has no URL",
+				html:          `<html><body><div><a href="http://home24.de"></a><a href="/home"></a></div></body></html>`,
+				expectedCount: 1,
+				pageURL:       "",
+			},
+		}
+
+		for _, tcase := range tests {
+			t.Run(tcase.name, func(t *testing.T) {
+
+				prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+				doc, _ := prsr.FromString(tcase.html, tcase.pageURL)
+				count, err := analyze[parser.ExternalLinkCount](parser.NewExternalLinkCountAnalyzer(), doc)
+				if err != nil {
+					t.Fatalf("Unexpecter error: %v", err)
+				}
+				if count != tcase.expectedCount {
+					t.Fatalf("Expected count %v, got %v", tcase.expectedCount, count)
+				}
+			})
+		}
+	})
+}
+
+func TestInternalLinkCountAnalyzer(t *testing.T) {
+	t.Parallel()
+	t.Run("should return error when document is not loaded", func(t *testing.T) {
+
+		doc := &parser.WebPageDocument{}
+
+		count, err := analyze[parser.InternalLinkCount](parser.NewInternalLinkCountAnalyzer(), doc)
+
+		if err == nil {
+			t.Fatalf("Expected error but got none")
+		}
+
+		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		}
+
+		if count != 0 {
+			t.Fatalf("Expected count of 0, got %v", count)
+		}
+	})
+
+	t.Run("should return correct internal link count", func(t *testing.T) {
+
+		tests := []struct {
+			name          string
+			html          string
+			expectedCount parser.InternalLinkCount
+			pageURL       string
+		}{
+			{
+				name:          "one internal relative link",
+				html:          `<html><body><a href="/home"></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "one internal full path link",
+				html:          `<html><body><a href="http://localhost/home"></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "two internal links",
+				html:          `<html><body><div><a href="http://localhost/home"></a><a href="/contact"></a></div></body></html>`,
+				expectedCount: 2,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "ignore external links",
+				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="/home"></a><a hrel="http://localhost/contact"></a></div></body></html>`,
+				expectedCount: 1,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "no links",
+				html:          `<html><body><div></div></body></html>`,
+				expectedCount: 0,
+				pageURL:       "http://localhost",
+			},
+			{
+				name:          "sub domains don't count as internal",
+				html:          `<html><body><div><a href="http://support.home24.de"></a></div></body></html>`,
+				expectedCount: 0,
+				pageURL:       "http://home24.de",
+			},
+		}
+
+		for _, tcase := range tests {
+			t.Run(tcase.name, func(t *testing.T) {
+
+				prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+				doc, _ := prsr.FromString(tcase.html, tcase.pageURL)
+				count, err := analyze[parser.InternalLinkCount](parser.NewInternalLinkCountAnalyzer(), doc)
+				if err != nil {
+					t.Fatalf("Unexpecter error: %v", err)
+				}
+				if count != tcase.expectedCount {
+					t.Fatalf("Expected count %v, got %v", tcase.expectedCount, count)
+				}
+			})
+		}
+	})
+}
+
+func TestInaccessibleLinksAnalyzer(t *testing.T) {
+	t.Parallel()
+	t.Run("should return error when document is not loaded", func(t *testing.T) {
+
+		doc := &parser.WebPageDocument{}
+
+		_, err := analyze[parser.InaccessibleLinks](newInaccessibleLinksAnalyzer(), doc)
+
+		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		}
+	})
+
+	t.Run("should return links that are not accessible", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+		mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closedURL := closed.URL + "/gone"
+		closed.Close()
+
+		content := fmt.Sprintf(`<html><body>
+			<a href="/ok"></a>
+			<a href="/ok#section"></a>
+			<a href="no-head"></a>
+			<a href="/missing"></a>
+			<a href="%v/broken"></a>
+			<a href="%v"></a>
+			<a href="mailto:info@home24.de"></a>
+			<a href="#top"></a>
+		</body></html>`, srv.URL, closedURL)
+
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+		doc, err := prsr.FromString(content, srv.URL+"/")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		links, err := analyze[parser.InaccessibleLinks](newInaccessibleLinksAnalyzer(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(links) != 3 {
+			t.Fatalf("Expected 3 inaccessible links, got %v: %+v", len(links), links)
+		}
+		if links[0].URL != srv.URL+"/missing" || links[0].StatusCode != http.StatusNotFound {
+			t.Errorf("Expected %v/missing with status 404, got %+v", srv.URL, links[0])
+		}
+		if links[1].URL != srv.URL+"/broken" || links[1].StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected %v/broken with status 500, got %+v", srv.URL, links[1])
+		}
+		if links[2].URL != closedURL || links[2].Error == "" {
+			t.Errorf("Expected %v with a network error, got %+v", closedURL, links[2])
+		}
+	})
+
+	t.Run("should not report the addresses of forbidden links", func(t *testing.T) {
+		pageFetcher := newInternalFetcher(t)
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).FromString(`<html><body><a href="http://intranet.home24.de/admin"></a></body></html>`, "https://www.home24.de/")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		links, err := analyze[parser.InaccessibleLinks](parser.NewInaccessibleLinksAnalyzer(parser.NewLinkChecker(pageFetcher, 0, 0)), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(links) != 1 || links[0].Error != ports.ErrForbiddenURL.Error() {
+			t.Fatalf("Expected the link to be forbidden, got %+v", links)
+		}
+		if b, _ := json.Marshal(links); strings.Contains(string(b), internalAddr) {
+			t.Fatalf("Expected the address not to be reported, got %s", b)
+		}
+	})
+
+	t.Run("should only send credentials to the host of the page", func(t *testing.T) {
+		external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer external.Close()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				fmt.Fprintf(w, `<html><body><a href="/private"></a><a href="%v/public"></a></body></html>`, external.URL)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer srv.Close()
+
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+		doc, err := prsr.DownloadDocument(context.Background(), srv.URL+"/", model.FetchOptions{BearerToken: "token"})
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		links, err := analyze[parser.InaccessibleLinks](newInaccessibleLinksAnalyzer(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(links) != 0 {
+			t.Fatalf("Expected all links to be accessible, got %+v", links)
+		}
+	})
+}
+
+func TestContainsLoginAnalyzer(t *testing.T) {
+	t.Parallel()
+	t.Run("should return error when document is not loaded", func(t *testing.T) {
+
+		doc := &parser.WebPageDocument{}
+
+		_, err := analyze[parser.ContainsLogin](parser.NewContainsLoginAnalyzer(), doc)
+
+		if err == nil {
+			t.Fatalf("Expected error but got none")
+		}
+
+		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		}
+
+	})
+
+	t.Run("should return true when html has a login form", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			html            string
+			expectedOutcome parser.ContainsLogin
+		}{
+			{
+				name:            "contains a login form with user and password",
+				html:            `<html><body><form><input type="text"></input><input type="password"></input></form></body></html>`,
+				expectedOutcome: true,
+			},
+			{
+				name:            "contains a login form with email and password",
+				html:            `<html><body><form><input type="email"></input><input type="password"></input></form></body></html>`,
+				expectedOutcome: true,
+			},
+			{
+				name:            "contains form with email and no password",
+				html:            `<html><body><form><input type="email"></input></form></body></html>`,
+				expectedOutcome: false,
+			},
+			{
+				name:            "contains a form with only password",
+				html:            `<html><body><form><input type="password"></input></form></body></html>`,
+				expectedOutcome: false,
+			},
+			{
+				name:            "contains an empty form",
+				html:            `<html><body><form></form></body></html>`,
+				expectedOutcome: false,
+			},
+			{
+				name:            "contains no form",
+				html:            `<html><body></body></html>`,
+				expectedOutcome: false,
+			},
+		}
+
+		for _, tcase := range tests {
+			t.Run(tcase.name, func(t *testing.T) {
+				prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+				doc, err := prsr.FromString(tcase.html, "http://localhost")
+				if err != nil {
+					t.Fatalf("Unexpected error: could not load document")
+				}
+
+				result, err := analyze[parser.ContainsLogin](parser.NewContainsLoginAnalyzer(), doc)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if result != tcase.expectedOutcome {
+					t.Fatalf("Expected outcome: %v, actual: %v", tcase.expectedOutcome, result)
+				}
+			})
+		}
+	})
+
+}
+
+func TestHeadingCountAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		html    string
+		h1Count int
+		h2Count int
+		h3Count int
+		h4Count int
+		h5Count int
+		h6Count int
+	}{
+		{
+			name:    "no headers",
+			html:    "<html><body><p>No headers here</p></body></html>",
+			h1Count: 0, h2Count: 0, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
+		},
+		{
+			name:    "single header of each type",
+			html:    "<html><body><h1>H1</h1><h2>H2</h2><h3>H3</h3><h4>H4</h4><h5>H5</h5><h6>H6</h6></body></html>",
+			h1Count: 1, h2Count: 1, h3Count: 1, h4Count: 1, h5Count: 1, h6Count: 1,
+		},
+		{
+			name:    "multiple headers of same type",
+			html:    "<html><body><h1>First</h1><h1>Second</h1><h2>H2</h2></body></html>",
+			h1Count: 2, h2Count: 1, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
+		},
+		{
+			name:    "nested headers",
+			html:    "<html><body><div><h1>Title</h1><section><h2>Section</h2></section></div></body></html>",
+			h1Count: 1, h2Count: 1, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+			doc, err := prsr.FromString(tt.html, "")
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			expected := []int{tt.h1Count, tt.h2Count, tt.h3Count, tt.h4Count, tt.h5Count, tt.h6Count}
+			for level := 1; level <= len(expected); level++ {
+				count, err := analyze[parser.HeadingCount](parser.NewHeadingCountAnalyzer(level), doc)
+				if err != nil {
+					t.Errorf("Unexpected error for H%d: %v", level, err)
+				}
+				if count.Count != expected[level-1] {
+					t.Errorf("Expected H%d count %d, got %d", level, expected[level-1], count.Count)
+				}
+			}
+		})
+	}
+}
+
+func TestHeadingCountAnalyzer_DocumentNotLoaded(t *testing.T) {
+	t.Parallel()
+	doc := &parser.WebPageDocument{}
+
+	for level := 1; level <= 6; level++ {
+		t.Run(fmt.Sprintf("h%d", level), func(t *testing.T) {
+			count, err := analyze[parser.HeadingCount](parser.NewHeadingCountAnalyzer(level), doc)
+
+			if err == nil {
+				t.Error("Expected error but got none")
+			}
+
+			if !errors.Is(err, parser.ErrDocumentNotLoaded) {
+				t.Errorf("Expected ErrDocumentNotLoaded, got %v", err)
+			}
+
+			if count.Count != 0 {
+				t.Errorf("Expected count 0, got %d", count.Count)
+			}
+		})
+	}
+}
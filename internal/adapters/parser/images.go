@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+const defaultImageCheckConcurrency = 10
+const defaultImageCheckTimeout = 5 * time.Second
+
+// Issues of an image.
+const (
+	ImageMissingAlt        = "missing_alt"
+	ImageEmptyAlt          = "empty_alt"
+	ImageFilenameAlt       = "filename_alt"
+	ImageMissingDimensions = "missing_dimensions"
+)
+
+// regexFilenameAlt matches alt texts that are file names, such as "sofa.jpg" or
+// "IMG_1234", rather than descriptions.
+var regexFilenameAlt = regexp.MustCompile(`(?i)^([\w\-. ]+\.(jpe?g|png|gif|webp|avif|svg|bmp|tiff?)|(img|dsc|dcim|image|photo|pic)[\-_ ]?\d+)$`)
+
+type ImagesConfig struct {
+	// Fetcher sends a HEAD request for every image to find out its size and
+	// content type. Nil skips them.
+	Fetcher ports.Fetcher
+	// Concurrency is the number of images requested at the same time.
+	Concurrency int
+	// Timeout is the deadline of the request of a single image.
+	Timeout time.Duration
+}
+
+// ImagesResult describes the images of a page. TotalSize is the sum of the
+// sizes that are known, counting every source once, and is only set when the
+// images were requested.
+type ImagesResult struct {
+	Count                  int         `json:"count"`
+	MissingAltCount        int         `json:"missingAltCount"`
+	PoorAltCount           int         `json:"poorAltCount"`
+	MissingDimensionsCount int         `json:"missingDimensionsCount"`
+	LazyCount              int         `json:"lazyCount"`
+	ResponsiveCount        int         `json:"responsiveCount"`
+	TotalSize              int64       `json:"totalSize,omitempty"`
+	Images                 []ImageInfo `json:"images"`
+}
+
+// Apply implements the Result interface.
+func (r ImagesResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("images", r)
+}
+
+// ImageInfo describes a single <img>. Src is empty when the image has no src,
+// such as the ones loaded by a script from data-src. Responsive images have a
+// srcset or are part of a <picture>. Size and ContentType are only set when the
+// image was requested, and Error when that request failed.
+type ImageInfo struct {
+	Src         string   `json:"src"`
+	Alt         *string  `json:"alt"`
+	Lazy        bool     `json:"lazy"`
+	Responsive  bool     `json:"responsive"`
+	Issues      []string `json:"issues,omitempty"`
+	Size        int64    `json:"size,omitempty"`
+	ContentType string   `json:"contentType,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// ImagesAnalyzer checks the alt texts, dimensions, lazy loading and responsive
+// sources of the images of a page, and optionally their weight.
+type ImagesAnalyzer struct {
+	cfg ImagesConfig
+}
+
+func NewImagesAnalyzer(cfg ImagesConfig) *ImagesAnalyzer {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = defaultImageCheckConcurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultImageCheckTimeout
+	}
+	return &ImagesAnalyzer{cfg: cfg}
+}
+
+// Name implements the Analyzer interface.
+func (a *ImagesAnalyzer) Name() string {
+	return "images"
+}
+
+// Analyze implements the Analyzer interface.
+func (a *ImagesAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	root := document.Root()
+	if root == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	nodes, err := htmlquery.QueryAll(root, "//img")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	result := ImagesResult{Count: len(nodes), Images: []ImageInfo{}}
+	for _, n := range nodes {
+		image := imageInfo(n, document.URL())
+		for _, issue := range image.Issues {
+			switch issue {
+			case ImageMissingAlt:
+				result.MissingAltCount++
+			case ImageEmptyAlt, ImageFilenameAlt:
+				result.PoorAltCount++
+			case ImageMissingDimensions:
+				result.MissingDimensionsCount++
+			}
+		}
+		if image.Lazy {
+			result.LazyCount++
+		}
+		if image.Responsive {
+			result.ResponsiveCount++
+		}
+		result.Images = append(result.Images, image)
+	}
+
+	if a.cfg.Fetcher != nil {
+		a.request(ctx, result.Images, document)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		counted := map[string]bool{}
+		for _, image := range result.Images {
+			if !counted[image.Src] {
+				counted[image.Src] = true
+				result.TotalSize += image.Size
+			}
+		}
+	}
+	return result, nil
+}
+
+func imageInfo(n *html.Node, pageURL string) ImageInfo {
+	image := ImageInfo{
+		Lazy: strings.EqualFold(strings.TrimSpace(htmlquery.SelectAttr(n, "loading")), "lazy"),
+	}
+	// An empty src would resolve to the page itself.
+	if src := strings.TrimSpace(htmlquery.SelectAttr(n, "src")); src != "" {
+		image.Src = resolveURL(pageURL, src)
+	}
+	if alt, ok := attr(n, "alt"); ok {
+		alt = strings.TrimSpace(alt)
+		image.Alt = &alt
+		switch {
+		case alt == "":
+			image.Issues = append(image.Issues, ImageEmptyAlt)
+		case regexFilenameAlt.MatchString(alt):
+			image.Issues = append(image.Issues, ImageFilenameAlt)
+		}
+	} else {
+		image.Issues = append(image.Issues, ImageMissingAlt)
+	}
+	if !hasAttr(n, "width") || !hasAttr(n, "height") {
+		image.Issues = append(image.Issues, ImageMissingDimensions)
+	}
+	image.Responsive = hasAttr(n, "srcset") || (n.Parent != nil && n.Parent.Type == html.ElementNode && n.Parent.Data == "picture")
+	return image
+}
+
+// request probes every http(s) source, a few at a time, and sets the size and
+// content type of the images that use it. Images without a source are skipped,
+// and those that are not requested before ctx is done are left as they are.
+// The requests use the options document was downloaded with, whose credentials
+// are only sent to the host of the page.
+func (a *ImagesAnalyzer) request(ctx context.Context, images []ImageInfo, document ports.Document) {
+	opts := document.FetchOptions()
+	opts.Timeout = a.cfg.Timeout
+	var pageHost string
+	if u, err := url.Parse(document.URL()); err == nil {
+		pageHost = u.Host
+	}
+	var sources []string
+	uses := map[string][]int{}
+	for i, image := range images {
+		if u, err := url.Parse(image.Src); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		if _, ok := uses[image.Src]; !ok {
+			sources = append(sources, image.Src)
+		}
+		uses[image.Src] = append(uses[image.Src], i)
+	}
+
+	sem := make(chan struct{}, a.cfg.Concurrency)
+	var wg sync.WaitGroup
+requesting:
+	for _, src := range sources {
+		select {
+		case <-ctx.Done():
+			break requesting
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			srcOpts := opts
+			if u, err := url.Parse(src); err != nil || u.Host != pageHost {
+				srcOpts = opts.WithoutCredentials()
+			}
+			size, contentType, errMessage := a.probe(ctx, src, srcOpts)
+			for _, i := range uses[src] {
+				images[i].Size, images[i].ContentType, images[i].Error = size, contentType, errMessage
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// probe returns the size and content type of the image at src, or the message
+// of the error the request failed with. Like LinkChecker.probe, it sends a
+// HEAD request and falls back to GET when the server refuses it.
+func (a *ImagesAnalyzer) probe(ctx context.Context, src string, opts model.FetchOptions) (int64, string, string) {
+	res, err := a.cfg.Fetcher.Fetch(ctx, http.MethodHead, src, opts)
+	if err != nil || res.StatusCode >= http.StatusBadRequest {
+		if err == nil {
+			res.Body.Close()
+		}
+		res, err = a.cfg.Fetcher.Fetch(ctx, http.MethodGet, src, opts)
+	}
+	if err != nil {
+		return 0, "", fetchErrorMessage(err)
+	}
+	res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return 0, "", res.Status
+	}
+	return max(res.ContentLength, 0), res.Header.Get("Content-Type"), ""
+}
@@ -0,0 +1,207 @@
+package parser_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+func TestImagesAnalyzer(t *testing.T) {
+	t.Parallel()
+	html := `<html><body>
+		<img src="/sofa.jpg" alt="Grey corner sofa" width="400" height="300" loading="lazy">
+		<img src="/chair.png">
+		<img src="/lamp.webp" alt="" width="10" height="10" srcset="/lamp-2x.webp 2x">
+		<picture><source srcset="/table.avif"><img src="/missing.jpg" alt="IMG_1234" width="1"></picture>
+		<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" alt="table.jpg" width="1" height="1">
+	</body></html>`
+
+	t.Run("should check alt texts, dimensions, lazy loading and responsive images", func(t *testing.T) {
+		doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString(html, "https://www.home24.de/sofas")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewImagesAnalyzer(parser.ImagesConfig{}).Analyze(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := result.(parser.ImagesResult)
+		if images.Count != 5 || images.MissingAltCount != 1 || images.PoorAltCount != 3 ||
+			images.MissingDimensionsCount != 2 || images.LazyCount != 1 || images.ResponsiveCount != 2 {
+			t.Errorf("Unexpected counts %+v", images)
+		}
+		expectedIssues := [][]string{
+			nil,
+			{parser.ImageMissingAlt, parser.ImageMissingDimensions},
+			{parser.ImageEmptyAlt},
+			{parser.ImageFilenameAlt, parser.ImageMissingDimensions},
+			{parser.ImageFilenameAlt},
+		}
+		for i, image := range images.Images {
+			if fmt.Sprint(image.Issues) != fmt.Sprint(expectedIssues[i]) {
+				t.Errorf("Expected issues %v for %v, got %v", expectedIssues[i], image.Src, image.Issues)
+			}
+		}
+		if images.Images[0].Src != "https://www.home24.de/sofa.jpg" {
+			t.Errorf("Expected the source to be resolved, got %v", images.Images[0].Src)
+		}
+		if images.TotalSize != 0 || images.Images[0].Size != 0 {
+			t.Errorf("Expected no sizes without a fetcher, got %+v", images)
+		}
+	})
+
+	t.Run("should request the images when there is a fetcher", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead && r.URL.Path != "/missing.jpg" {
+				t.Errorf("Expected a HEAD request, got %v", r.Method)
+			}
+			if r.URL.Path == "/missing.jpg" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Header().Set("Content-Length", "1000")
+		}))
+		defer srv.Close()
+		pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{})
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).FromString(html, srv.URL)
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewImagesAnalyzer(parser.ImagesConfig{Fetcher: pageFetcher}).Analyze(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := result.(parser.ImagesResult)
+		if images.TotalSize != 3000 {
+			t.Errorf("Expected a total size of 3000, got %v", images.TotalSize)
+		}
+		if image := images.Images[0]; image.Size != 1000 || image.ContentType != "image/jpeg" {
+			t.Errorf("Expected the size and content type, got %+v", image)
+		}
+		if image := images.Images[3]; image.Error != "404 Not Found" {
+			t.Errorf("Expected the missing image to fail, got %+v", image)
+		}
+		if image := images.Images[4]; image.Size != 0 || image.Error != "" {
+			t.Errorf("Expected data URIs not to be requested, got %+v", image)
+		}
+	})
+
+	t.Run("should request every source once and skip images without one", func(t *testing.T) {
+		var mu sync.Mutex
+		requests := map[string]int{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[r.URL.Path]++
+			mu.Unlock()
+			w.Header().Set("Content-Length", "1000")
+		}))
+		defer srv.Close()
+		pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{})
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).FromString(`<html><body>
+			<img src="/sofa.jpg" alt="Sofa">
+			<img data-src="/lazy.jpg" alt="Lazy">
+			<img src=" " alt="Blank">
+			<img src="/sofa.jpg" alt="Sofa">
+		</body></html>`, srv.URL+"/page")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewImagesAnalyzer(parser.ImagesConfig{Fetcher: pageFetcher}).Analyze(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := result.(parser.ImagesResult)
+		if images.Images[1].Src != "" || images.Images[2].Src != "" {
+			t.Errorf("Expected no source for images without src, got %+v", images.Images)
+		}
+		if fmt.Sprint(requests) != "map[/sofa.jpg:1]" {
+			t.Errorf("Expected a single request for /sofa.jpg, got %v", requests)
+		}
+		if images.Images[0].Size != 1000 || images.Images[3].Size != 1000 {
+			t.Errorf("Expected the size of every image using the source, got %+v", images.Images)
+		}
+		if images.TotalSize != 1000 {
+			t.Errorf("Expected a total size of 1000, got %v", images.TotalSize)
+		}
+	})
+
+	t.Run("should not report the addresses of forbidden images", func(t *testing.T) {
+		pageFetcher := newInternalFetcher(t)
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).FromString(`<html><body><img src="http://intranet.home24.de/logo.png" alt="Logo"></body></html>`, "https://www.home24.de/")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewImagesAnalyzer(parser.ImagesConfig{Fetcher: pageFetcher}).Analyze(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := result.(parser.ImagesResult)
+		if images.Images[0].Error != ports.ErrForbiddenURL.Error() {
+			t.Fatalf("Expected the image to be forbidden, got %+v", images.Images[0])
+		}
+		if b, _ := json.Marshal(images); strings.Contains(string(b), internalAddr) {
+			t.Fatalf("Expected the address not to be reported, got %s", b)
+		}
+	})
+
+	t.Run("should fall back to GET and use the options of the document", func(t *testing.T) {
+		external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Length", "200")
+		}))
+		defer external.Close()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				fmt.Fprintf(w, `<html><body><img src="/no-head.png" alt="Logo"><img src="%v/public.png" alt="Banner"></body></html>`, external.URL)
+				return
+			}
+			if r.Method == http.MethodHead || r.Header.Get("Authorization") != "Bearer token" || r.UserAgent() != "custom" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "100")
+		}))
+		defer srv.Close()
+		pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{})
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).DownloadDocument(context.Background(), srv.URL+"/", model.FetchOptions{UserAgent: "custom", BearerToken: "token"})
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewImagesAnalyzer(parser.ImagesConfig{Fetcher: pageFetcher}).Analyze(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := result.(parser.ImagesResult)
+		if image := images.Images[0]; image.Size != 100 || image.ContentType != "image/png" || image.Error != "" {
+			t.Errorf("Expected the image to be requested with GET and the options of the page, got %+v", image)
+		}
+		if image := images.Images[1]; image.Size != 200 || image.Error != "" {
+			t.Errorf("Expected the image of another host to be requested without credentials, got %+v", image)
+		}
+	})
+}
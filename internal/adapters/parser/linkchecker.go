@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const defaultLinkCheckConcurrency = 10
+const defaultLinkCheckTimeout = 5 * time.Second
+
+// LinkChecker probes links to find out which of them are not accessible.
+type LinkChecker struct {
+	fetcher     ports.Fetcher
+	concurrency int
+	timeout     time.Duration
+}
+
+// NewLinkChecker returns a LinkChecker that probes at most concurrency links at
+// the same time with fetcher and gives up on a single link after timeout.
+func NewLinkChecker(fetcher ports.Fetcher, concurrency int, timeout time.Duration) *LinkChecker {
+	if concurrency < 1 {
+		concurrency = defaultLinkCheckConcurrency
+	}
+	if timeout <= 0 {
+		timeout = defaultLinkCheckTimeout
+	}
+	return &LinkChecker{
+		fetcher:     fetcher,
+		concurrency: concurrency,
+		timeout:     timeout,
+	}
+}
+
+// Check probes every link of the page at base and returns the ones that could
+// not be accessed, in the same order as they were given. Links that were not
+// probed before ctx was done are left out. The credentials of opts are only sent
+// to the host of the page.
+func (c *LinkChecker) Check(ctx context.Context, base *url.URL, links []string, opts model.FetchOptions) []model.InaccessibleLink {
+	opts.Timeout = c.timeout
+	results := make([]*model.InaccessibleLink, len(links))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+probing:
+	for i, link := range links {
+		select {
+		case <-ctx.Done():
+			break probing
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			linkOpts := opts
+			if u, err := url.Parse(link); err != nil || u.Host != base.Host {
+				linkOpts = opts.WithoutCredentials()
+			}
+			results[i] = c.probe(ctx, link, linkOpts)
+		}()
+	}
+	wg.Wait()
+
+	inaccessible := []model.InaccessibleLink{}
+	for _, r := range results {
+		if r != nil {
+			inaccessible = append(inaccessible, *r)
+		}
+	}
+	return inaccessible
+}
+
+// probe sends a HEAD request to the link and falls back to GET when the server
+// refuses it, as plenty of servers do not implement HEAD properly.
+func (c *LinkChecker) probe(ctx context.Context, link string, opts model.FetchOptions) *model.InaccessibleLink {
+	status, err := c.request(ctx, http.MethodHead, link, opts)
+	if err != nil || status >= http.StatusBadRequest {
+		status, err = c.request(ctx, http.MethodGet, link, opts)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return &model.InaccessibleLink{URL: link, Error: fetchErrorMessage(err)}
+	}
+	if status >= http.StatusBadRequest {
+		return &model.InaccessibleLink{URL: link, StatusCode: status}
+	}
+	return nil
+}
+
+func (c *LinkChecker) request(ctx context.Context, method string, link string, opts model.FetchOptions) (int, error) {
+	res, err := c.fetcher.Fetch(ctx, method, link, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	return res.StatusCode, nil
+}
+
+// fetchErrorMessage describes why a request sent on behalf of a page failed.
+// The messages are fixed, as the errors of the fetcher may hold the addresses
+// hosts resolve to, which would let anyone map internal networks by reporting
+// on a page that links to them.
+func fetchErrorMessage(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ports.ErrForbiddenURL):
+		return ports.ErrForbiddenURL.Error()
+	case errors.Is(err, ports.ErrTooManyRedirects):
+		return ports.ErrTooManyRedirects.Error()
+	case errors.As(err, &dnsErr):
+		return "host could not be resolved"
+	case errors.As(err, &certErr):
+		return "certificate is not valid"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return ports.ErrUnreachableHost.Error()
+	}
+}
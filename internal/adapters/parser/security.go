@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// minHSTSMaxAge is the shortest max-age of Strict-Transport-Security that is
+// not considered too short, 180 days.
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// insecureCookiePenalty is the score taken away for every cookie that does not
+// pass, up to maxCookiePenalty.
+const insecureCookiePenalty = 5
+const maxCookiePenalty = 20
+
+type FindingStatus string
+
+const (
+	FindingPass FindingStatus = "pass"
+	FindingWarn FindingStatus = "warn"
+	FindingFail FindingStatus = "fail"
+)
+
+// SecurityHeadersResult grades the security headers of the response a page was
+// served with. Score goes from 0 to 100, and Grade from A to F.
+type SecurityHeadersResult struct {
+	Grade   string          `json:"grade"`
+	Score   int             `json:"score"`
+	Headers []HeaderFinding `json:"headers"`
+	Cookies []CookieFinding `json:"cookies"`
+}
+
+// Apply implements the Result interface.
+func (r SecurityHeadersResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("securityHeaders", r)
+}
+
+type HeaderFinding struct {
+	Header  string        `json:"header"`
+	Value   string        `json:"value,omitempty"`
+	Status  FindingStatus `json:"status"`
+	Message string        `json:"message,omitempty"`
+}
+
+// CookieFinding describes the flags of a cookie set by the response.
+type CookieFinding struct {
+	Name     string        `json:"name"`
+	Secure   bool          `json:"secure"`
+	HttpOnly bool          `json:"httpOnly"`
+	SameSite string        `json:"sameSite,omitempty"`
+	Status   FindingStatus `json:"status"`
+	Issues   []string      `json:"issues,omitempty"`
+}
+
+// headerCheck grades a single header. Passing headers score their whole
+// weight, and headers with warnings half of it.
+type headerCheck struct {
+	header string
+	weight int
+	check  func(value string, header http.Header, https bool) (FindingStatus, string)
+}
+
+var headerChecks = []headerCheck{
+	{"Content-Security-Policy", 25, checkContentSecurityPolicy},
+	{"Strict-Transport-Security", 25, checkStrictTransportSecurity},
+	{"X-Frame-Options", 15, checkFrameOptions},
+	{"X-Content-Type-Options", 15, checkContentTypeOptions},
+	{"Referrer-Policy", 10, checkReferrerPolicy},
+	{"Permissions-Policy", 10, checkPermissionsPolicy},
+}
+
+// SecurityHeadersAnalyzer grades the HTTP security headers and the cookie
+// flags of the response a page was served with.
+type SecurityHeadersAnalyzer struct{}
+
+func NewSecurityHeadersAnalyzer() *SecurityHeadersAnalyzer {
+	return &SecurityHeadersAnalyzer{}
+}
+
+// Name implements the Analyzer interface.
+func (a *SecurityHeadersAnalyzer) Name() string {
+	return "securityHeaders"
+}
+
+// Analyze implements the Analyzer interface. Documents that were not
+// downloaded have no headers to grade, and their result is nil.
+func (a *SecurityHeadersAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	header := document.Header()
+	if header == nil {
+		return nil, nil
+	}
+	u, err := url.Parse(document.URL())
+	https := err == nil && u.Scheme == "https"
+
+	result := SecurityHeadersResult{Headers: []HeaderFinding{}, Cookies: []CookieFinding{}}
+	for _, c := range headerChecks {
+		value := strings.TrimSpace(header.Get(c.header))
+		status, message := c.check(value, header, https)
+		switch status {
+		case FindingPass:
+			result.Score += c.weight
+		case FindingWarn:
+			result.Score += c.weight / 2
+		}
+		result.Headers = append(result.Headers, HeaderFinding{c.header, value, status, message})
+	}
+
+	penalty := 0
+	for _, line := range header.Values("Set-Cookie") {
+		cookie, err := http.ParseSetCookie(line)
+		if err != nil {
+			continue
+		}
+		finding := checkCookie(cookie)
+		if finding.Status != FindingPass {
+			penalty += insecureCookiePenalty
+		}
+		result.Cookies = append(result.Cookies, finding)
+	}
+	result.Score = max(result.Score-min(penalty, maxCookiePenalty), 0)
+	result.Grade = grade(result.Score)
+	return result, nil
+}
+
+func grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+func checkContentSecurityPolicy(value string, header http.Header, https bool) (FindingStatus, string) {
+	if value == "" {
+		if header.Get("Content-Security-Policy-Report-Only") != "" {
+			return FindingWarn, "the policy is only reported, not enforced"
+		}
+		return FindingFail, "header is missing"
+	}
+	directives := parseDirectives(value)
+	sources, ok := directives["script-src"]
+	if !ok {
+		sources, ok = directives["default-src"]
+	}
+	if !ok {
+		return FindingWarn, "scripts are not restricted by script-src or default-src"
+	}
+	// 'unsafe-inline' is ignored by browsers when nonces or hashes are allowed.
+	hashed := false
+	for _, source := range sources {
+		if strings.HasPrefix(source, "'nonce-") || strings.HasPrefix(source, "'sha") {
+			hashed = true
+		}
+	}
+	for _, source := range sources {
+		switch {
+		case source == "*":
+			return FindingWarn, "scripts are allowed from any origin"
+		case source == "'unsafe-eval'":
+			return FindingWarn, "scripts are allowed to use eval"
+		case source == "'unsafe-inline'" && !hashed:
+			return FindingWarn, "inline scripts are allowed"
+		}
+	}
+	return FindingPass, ""
+}
+
+func checkStrictTransportSecurity(value string, header http.Header, https bool) (FindingStatus, string) {
+	if !https {
+		return FindingFail, "page is not served over HTTPS"
+	}
+	if value == "" {
+		return FindingFail, "header is missing"
+	}
+	maxAge, ok := parseDirectives(value)["max-age"]
+	if !ok || len(maxAge) != 1 {
+		return FindingFail, "max-age is missing"
+	}
+	seconds, err := strconv.Atoi(strings.Trim(maxAge[0], `"`))
+	switch {
+	case err != nil:
+		return FindingFail, "max-age is not a number"
+	case seconds <= 0:
+		return FindingFail, "max-age of 0 disables it"
+	case seconds < minHSTSMaxAge:
+		return FindingWarn, "max-age is shorter than 180 days"
+	}
+	return FindingPass, ""
+}
+
+func checkFrameOptions(value string, header http.Header, https bool) (FindingStatus, string) {
+	switch strings.ToUpper(value) {
+	case "DENY", "SAMEORIGIN":
+		return FindingPass, ""
+	case "":
+		if _, ok := parseDirectives(header.Get("Content-Security-Policy"))["frame-ancestors"]; ok {
+			return FindingPass, "framing is restricted by frame-ancestors"
+		}
+		return FindingFail, "header is missing"
+	}
+	return FindingWarn, "value is not supported by browsers"
+}
+
+func checkContentTypeOptions(value string, header http.Header, https bool) (FindingStatus, string) {
+	switch {
+	case strings.EqualFold(value, "nosniff"):
+		return FindingPass, ""
+	case value == "":
+		return FindingFail, "header is missing"
+	}
+	return FindingFail, "value must be nosniff"
+}
+
+func checkReferrerPolicy(value string, header http.Header, https bool) (FindingStatus, string) {
+	if value == "" {
+		return FindingWarn, "header is missing, browsers default to strict-origin-when-cross-origin"
+	}
+	// Browsers use the last policy they support, so that new ones can be
+	// listed after a fallback.
+	policies := strings.Split(value, ",")
+	switch strings.ToLower(strings.TrimSpace(policies[len(policies)-1])) {
+	case "no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin":
+		return FindingPass, ""
+	case "origin", "origin-when-cross-origin", "no-referrer-when-downgrade":
+		return FindingWarn, "the referrer is sent to other sites"
+	case "unsafe-url":
+		return FindingFail, "the full URL is sent to every site"
+	}
+	return FindingFail, "policy is not valid"
+}
+
+func checkPermissionsPolicy(value string, header http.Header, https bool) (FindingStatus, string) {
+	if value == "" {
+		return FindingWarn, "header is missing"
+	}
+	return FindingPass, ""
+}
+
+func checkCookie(cookie *http.Cookie) CookieFinding {
+	finding := CookieFinding{
+		Name:     cookie.Name,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		Status:   FindingPass,
+	}
+	switch cookie.SameSite {
+	case http.SameSiteStrictMode:
+		finding.SameSite = "Strict"
+	case http.SameSiteLaxMode:
+		finding.SameSite = "Lax"
+	case http.SameSiteNoneMode:
+		finding.SameSite = "None"
+	}
+
+	if !cookie.HttpOnly {
+		finding.Status = FindingWarn
+		finding.Issues = append(finding.Issues, "missing HttpOnly")
+	}
+	if finding.SameSite == "" {
+		finding.Status = FindingWarn
+		finding.Issues = append(finding.Issues, "missing SameSite")
+	}
+	if !cookie.Secure {
+		finding.Status = FindingFail
+		finding.Issues = append(finding.Issues, "missing Secure")
+		if finding.SameSite == "None" {
+			finding.Issues = append(finding.Issues, "SameSite=None is rejected without Secure")
+		}
+	}
+	return finding
+}
+
+// parseDirectives splits headers such as Content-Security-Policy into their
+// directives, by lower case name.
+func parseDirectives(value string) map[string][]string {
+	directives := map[string][]string{}
+	for _, directive := range strings.Split(value, ";") {
+		directive = strings.TrimSpace(directive)
+		name, values, _ := strings.Cut(directive, " ")
+		name, arg, hasArg := strings.Cut(name, "=")
+		name = strings.ToLower(name)
+		// Repeated directives are ignored, as browsers only use the first one.
+		if _, ok := directives[name]; ok || name == "" {
+			continue
+		}
+		if hasArg {
+			directives[name] = []string{arg}
+			continue
+		}
+		directives[name] = strings.Fields(strings.ToLower(values))
+	}
+	return directives
+}
@@ -0,0 +1,154 @@
+package parser_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+func TestSecurityHeadersAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		headers          map[string][]string
+		expectedGrade    string
+		expectedStatuses map[string]parser.FindingStatus
+		expectedCookies  []parser.CookieFinding
+	}{
+		{
+			name: "should fail every header when none are set",
+			// Strict-Transport-Security always fails, as the test server is not
+			// served over HTTPS.
+			expectedGrade: "F",
+			expectedStatuses: map[string]parser.FindingStatus{
+				"Content-Security-Policy":   parser.FindingFail,
+				"Strict-Transport-Security": parser.FindingFail,
+				"X-Frame-Options":           parser.FindingFail,
+				"X-Content-Type-Options":    parser.FindingFail,
+				"Referrer-Policy":           parser.FindingWarn,
+				"Permissions-Policy":        parser.FindingWarn,
+			},
+		},
+		{
+			name: "should pass strict headers",
+			headers: map[string][]string{
+				"Content-Security-Policy": {"default-src 'self'; frame-ancestors 'none'"},
+				"X-Content-Type-Options":  {"nosniff"},
+				"Referrer-Policy":         {"no-referrer"},
+				"Permissions-Policy":      {"camera=()"},
+			},
+			expectedGrade: "B",
+			expectedStatuses: map[string]parser.FindingStatus{
+				"Content-Security-Policy": parser.FindingPass,
+				"X-Frame-Options":         parser.FindingPass,
+				"X-Content-Type-Options":  parser.FindingPass,
+				"Referrer-Policy":         parser.FindingPass,
+				"Permissions-Policy":      parser.FindingPass,
+			},
+		},
+		{
+			name: "should warn about weak headers",
+			headers: map[string][]string{
+				"Content-Security-Policy": {"script-src 'self' 'unsafe-inline'"},
+				"X-Frame-Options":         {"ALLOW-FROM https://home24.de"},
+				"Referrer-Policy":         {"no-referrer-when-downgrade"},
+			},
+			expectedGrade: "F",
+			expectedStatuses: map[string]parser.FindingStatus{
+				"Content-Security-Policy": parser.FindingWarn,
+				"X-Frame-Options":         parser.FindingWarn,
+				"Referrer-Policy":         parser.FindingWarn,
+			},
+		},
+		{
+			name: "should ignore unsafe-inline when nonces are allowed",
+			headers: map[string][]string{
+				"Content-Security-Policy": {"script-src 'nonce-abc' 'unsafe-inline'"},
+			},
+			expectedGrade: "F",
+			expectedStatuses: map[string]parser.FindingStatus{
+				"Content-Security-Policy": parser.FindingPass,
+			},
+		},
+		{
+			name: "should check cookie flags",
+			headers: map[string][]string{
+				"Content-Security-Policy": {"default-src 'self'; frame-ancestors 'none'"},
+				"X-Content-Type-Options":  {"nosniff"},
+				"Referrer-Policy":         {"no-referrer"},
+				"Permissions-Policy":      {"camera=()"},
+				"Set-Cookie": {
+					"session=abc; Secure; HttpOnly; SameSite=Strict",
+					"tracking=xyz; SameSite=None",
+					"theme=dark; Secure",
+				},
+			},
+			expectedGrade: "C",
+			expectedCookies: []parser.CookieFinding{
+				{Name: "session", Secure: true, HttpOnly: true, SameSite: "Strict", Status: parser.FindingPass},
+				{Name: "tracking", SameSite: "None", Status: parser.FindingFail, Issues: []string{"missing HttpOnly", "missing Secure", "SameSite=None is rejected without Secure"}},
+				{Name: "theme", Secure: true, Status: parser.FindingWarn, Issues: []string{"missing HttpOnly", "missing SameSite"}},
+			},
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, values := range tcase.headers {
+					for _, value := range values {
+						w.Header().Add(name, value)
+					}
+				}
+				w.Header().Set("Content-Type", "text/html")
+			}))
+			defer srv.Close()
+			doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).DownloadDocument(context.Background(), srv.URL, model.FetchOptions{})
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			result, err := parser.NewSecurityHeadersAnalyzer().Analyze(context.Background(), doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			security := result.(parser.SecurityHeadersResult)
+			if security.Grade != tcase.expectedGrade {
+				t.Errorf("Expected grade %v, got %v (score %v)", tcase.expectedGrade, security.Grade, security.Score)
+			}
+			for _, finding := range security.Headers {
+				if expected, ok := tcase.expectedStatuses[finding.Header]; ok && finding.Status != expected {
+					t.Errorf("Expected %v to %v, got %+v", finding.Header, expected, finding)
+				}
+			}
+			if len(security.Cookies) != len(tcase.expectedCookies) {
+				t.Fatalf("Expected %v cookies, got %+v", len(tcase.expectedCookies), security.Cookies)
+			}
+			for i, expected := range tcase.expectedCookies {
+				if fmt.Sprint(security.Cookies[i]) != fmt.Sprint(expected) {
+					t.Errorf("Expected cookie %+v, got %+v", expected, security.Cookies[i])
+				}
+			}
+		})
+	}
+
+	t.Run("should have no result for documents that were not downloaded", func(t *testing.T) {
+		doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString("<html></html>", "https://home24.de")
+		if err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+
+		result, err := parser.NewSecurityHeadersAnalyzer().Analyze(context.Background(), doc)
+
+		if result != nil || err != nil {
+			t.Fatalf("Expected no result, got %v %v", result, err)
+		}
+	})
+}
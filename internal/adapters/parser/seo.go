@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Lengths above which search engines usually truncate titles and descriptions.
+const maxTitleLength = 60
+const maxDescriptionLength = 160
+
+// SEOResult describes the tags search engines read from a page. Lengths are in
+// characters.
+type SEOResult struct {
+	Title             string     `json:"title"`
+	TitleLength       int        `json:"titleLength"`
+	Description       string     `json:"description"`
+	DescriptionLength int        `json:"descriptionLength"`
+	Robots            string     `json:"robots,omitempty"`
+	Canonical         string     `json:"canonical,omitempty"`
+	HeaderOneCount    int        `json:"headerOneCount"`
+	Viewport          string     `json:"viewport,omitempty"`
+	Lang              string     `json:"lang,omitempty"`
+	Issues            []SEOIssue `json:"issues"`
+}
+
+// Apply implements the Result interface.
+func (r SEOResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("seo", r)
+}
+
+// SEOIssue is a common problem found on a page. Code is a stable identifier,
+// such as "missing_description".
+type SEOIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SEOAnalyzer extracts the meta tags, canonical URL, language and headings
+// search engines rely on, and flags common issues with them.
+type SEOAnalyzer struct{}
+
+func NewSEOAnalyzer() *SEOAnalyzer {
+	return &SEOAnalyzer{}
+}
+
+// Name implements the Analyzer interface.
+func (a *SEOAnalyzer) Name() string {
+	return "seo"
+}
+
+// Analyze implements the Analyzer interface.
+func (a *SEOAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	root := document.Root()
+	if root == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	result := SEOResult{Issues: []SEOIssue{}}
+	issue := func(code string, format string, args ...any) {
+		result.Issues = append(result.Issues, SEOIssue{code, fmt.Sprintf(format, args...)})
+	}
+
+	title, err := documentTitle(root)
+	if err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return nil, err
+	}
+	result.Title = strings.TrimSpace(title)
+	result.TitleLength = utf8.RuneCountInString(result.Title)
+
+	metas := []struct {
+		value *string
+		name  string
+	}{
+		{&result.Description, "description"},
+		{&result.Robots, "robots"},
+		{&result.Viewport, "viewport"},
+	}
+	for _, m := range metas {
+		*m.value, err = metaContent(root, "name", m.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.DescriptionLength = utf8.RuneCountInString(result.Description)
+
+	canonical, err := htmlquery.Query(root, "//link[lower-case(@rel)='canonical']")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	if canonical != nil {
+		result.Canonical = resolveURL(document.URL(), htmlquery.SelectAttr(canonical, "href"))
+	}
+	if page, err := htmlquery.Query(root, "//html"); err == nil && page != nil {
+		result.Lang = strings.TrimSpace(htmlquery.SelectAttr(page, "lang"))
+	}
+	result.HeaderOneCount, err = countElements(root, "//h1")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case result.Title == "":
+		issue("missing_title", "the page has no title")
+	case result.TitleLength > maxTitleLength:
+		issue("title_too_long", "the title is %d characters long, more than %d", result.TitleLength, maxTitleLength)
+	}
+	switch {
+	case result.Description == "":
+		issue("missing_description", "the page has no meta description")
+	case result.DescriptionLength > maxDescriptionLength:
+		issue("description_too_long", "the description is %d characters long, more than %d", result.DescriptionLength, maxDescriptionLength)
+	}
+	switch {
+	case result.HeaderOneCount == 0:
+		issue("missing_h1", "the page has no <h1>")
+	case result.HeaderOneCount > 1:
+		issue("multiple_h1", "the page has %d <h1> elements", result.HeaderOneCount)
+	}
+	if isNoIndex(result.Robots) {
+		issue("noindex", "the page asks not to be indexed")
+	} else if header := document.Header(); header != nil && isNoIndex(strings.Join(header.Values("X-Robots-Tag"), ",")) {
+		issue("noindex", "the X-Robots-Tag header asks not to index the page")
+	}
+	if result.Viewport == "" {
+		issue("missing_viewport", "the page has no viewport meta tag")
+	}
+	if result.Lang == "" {
+		issue("missing_lang", "the <html> element has no lang attribute")
+	}
+	return result, nil
+}
+
+// isNoIndex tells whether robots directives, such as "noindex, follow", keep
+// the page out of search results.
+func isNoIndex(robots string) bool {
+	for _, directive := range strings.FieldsFunc(strings.ToLower(robots), func(r rune) bool { return r == ',' || r == ' ' }) {
+		if directive == "noindex" || directive == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+// metaContent returns the trimmed content of the first <meta> element whose
+// attr, such as name or property, is name regardless of case.
+func metaContent(root *html.Node, attr string, name string) (string, error) {
+	meta, err := htmlquery.Query(root, fmt.Sprintf("//meta[lower-case(@%v)='%v']", attr, name))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	if meta == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(htmlquery.SelectAttr(meta, "content")), nil
+}
+
+// resolveURL resolves ref against the URL of the page, leaving it as it is
+// when either of them cannot be parsed.
+func resolveURL(pageURL string, ref string) string {
+	ref = strings.TrimSpace(ref)
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
@@ -0,0 +1,84 @@
+package parser_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+)
+
+func TestSEOAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		html           string
+		expected       func(result parser.SEOResult) bool
+		expectedIssues []string
+	}{
+		{
+			name: "should extract the tags of a well optimized page",
+			html: `<html lang="de"><head>
+				<title>Sofas online kaufen</title>
+				<meta name="Description" content=" Sofas in allen Farben. ">
+				<meta name="robots" content="index, follow">
+				<meta name="viewport" content="width=device-width, initial-scale=1">
+				<link rel="canonical" href="/sofas">
+			</head><body><h1>Sofas</h1><h2>Ecksofas</h2></body></html>`,
+			expected: func(result parser.SEOResult) bool {
+				return result.Title == "Sofas online kaufen" && result.TitleLength == 19 &&
+					result.Description == "Sofas in allen Farben." && result.DescriptionLength == 22 &&
+					result.Robots == "index, follow" && result.Canonical == "https://www.home24.de/sofas" &&
+					result.HeaderOneCount == 1 && result.Viewport != "" && result.Lang == "de"
+			},
+			expectedIssues: []string{},
+		},
+		{
+			name:           "should flag missing tags",
+			html:           `<html><head></head><body></body></html>`,
+			expected:       func(result parser.SEOResult) bool { return result.Title == "" && result.Canonical == "" },
+			expectedIssues: []string{"missing_title", "missing_description", "missing_h1", "missing_viewport", "missing_lang"},
+		},
+		{
+			name: "should flag long titles and descriptions, multiple h1s and noindex",
+			html: fmt.Sprintf(`<html lang="en"><head>
+				<title>%v</title>
+				<meta name="description" content="%v">
+				<meta name="robots" content="NOINDEX">
+				<meta name="viewport" content="width=device-width">
+			</head><body><h1>One</h1><h1>Two</h1></body></html>`, strings.Repeat("ü", 61), strings.Repeat("a", 161)),
+			expected: func(result parser.SEOResult) bool {
+				return result.TitleLength == 61 && result.DescriptionLength == 161 && result.HeaderOneCount == 2
+			},
+			expectedIssues: []string{"title_too_long", "description_too_long", "multiple_h1", "noindex"},
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString(tcase.html, "https://www.home24.de/moebel")
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			result, err := parser.NewSEOAnalyzer().Analyze(context.Background(), doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			seo := result.(parser.SEOResult)
+			if !tcase.expected(seo) {
+				t.Errorf("Unexpected result %+v", seo)
+			}
+			codes := []string{}
+			for _, issue := range seo.Issues {
+				codes = append(codes, issue.Code)
+			}
+			if fmt.Sprint(codes) != fmt.Sprint(tcase.expectedIssues) {
+				t.Errorf("Expected issues %v, got %v", tcase.expectedIssues, codes)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+)
+
+// requiredOpenGraph are the properties every Open Graph page must have.
+var requiredOpenGraph = []string{"og:title", "og:type", "og:image", "og:url"}
+
+// imageProperties hold image URLs, which are resolved against the URL of the
+// page as crawlers would.
+var imageProperties = map[string]bool{
+	"og:image":            true,
+	"og:image:url":        true,
+	"og:image:secure_url": true,
+	"twitter:image":       true,
+	"twitter:image:src":   true,
+}
+
+// SocialResult describes how a page is previewed when shared. OpenGraph and
+// Twitter hold every og:* and twitter:* property, as some can be repeated.
+type SocialResult struct {
+	Preview   SocialPreview       `json:"preview"`
+	OpenGraph map[string][]string `json:"openGraph"`
+	Twitter   map[string][]string `json:"twitter"`
+	Issues    []SocialIssue       `json:"issues"`
+}
+
+// Apply implements the Result interface.
+func (r SocialResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("social", r)
+}
+
+// SocialPreview is what a share of the page shows, taken from Open Graph and
+// falling back to Twitter cards.
+type SocialPreview struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	URL         string `json:"url"`
+	Type        string `json:"type"`
+	SiteName    string `json:"siteName"`
+	Card        string `json:"card"`
+}
+
+type SocialIssue struct {
+	Property string `json:"property"`
+	Message  string `json:"message"`
+}
+
+// SocialAnalyzer extracts the Open Graph and Twitter card properties of a page
+// and checks the ones Open Graph requires.
+type SocialAnalyzer struct{}
+
+func NewSocialAnalyzer() *SocialAnalyzer {
+	return &SocialAnalyzer{}
+}
+
+// Name implements the Analyzer interface.
+func (a *SocialAnalyzer) Name() string {
+	return "social"
+}
+
+// Analyze implements the Analyzer interface.
+func (a *SocialAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	root := document.Root()
+	if root == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	metas, err := htmlquery.QueryAll(root, "//meta[@property or @name]")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	result := SocialResult{
+		OpenGraph: map[string][]string{},
+		Twitter:   map[string][]string{},
+		Issues:    []SocialIssue{},
+	}
+	for _, meta := range metas {
+		// Open Graph uses property and Twitter name, but pages mix them up and
+		// crawlers accept both.
+		property := htmlquery.SelectAttr(meta, "property")
+		if property == "" {
+			property = htmlquery.SelectAttr(meta, "name")
+		}
+		property = strings.ToLower(strings.TrimSpace(property))
+		content := strings.TrimSpace(htmlquery.SelectAttr(meta, "content"))
+		if content == "" {
+			continue
+		}
+		if imageProperties[property] {
+			content = resolveURL(document.URL(), content)
+		}
+		switch {
+		case strings.HasPrefix(property, "og:"):
+			result.OpenGraph[property] = append(result.OpenGraph[property], content)
+		case strings.HasPrefix(property, "twitter:"):
+			result.Twitter[property] = append(result.Twitter[property], content)
+		}
+	}
+
+	first := func(properties map[string][]string, name string) string {
+		if values := properties[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	preview := func(og string, twitter string) string {
+		if value := first(result.OpenGraph, og); value != "" {
+			return value
+		}
+		return first(result.Twitter, twitter)
+	}
+	result.Preview = SocialPreview{
+		Title:       preview("og:title", "twitter:title"),
+		Description: preview("og:description", "twitter:description"),
+		Image:       preview("og:image", "twitter:image"),
+		URL:         first(result.OpenGraph, "og:url"),
+		Type:        first(result.OpenGraph, "og:type"),
+		SiteName:    preview("og:site_name", "twitter:site"),
+		Card:        first(result.Twitter, "twitter:card"),
+	}
+
+	for _, property := range requiredOpenGraph {
+		if first(result.OpenGraph, property) == "" {
+			result.Issues = append(result.Issues, SocialIssue{property, "required property is missing"})
+		}
+	}
+	if u := first(result.OpenGraph, "og:url"); u != "" && !isAbsoluteURL(u) {
+		result.Issues = append(result.Issues, SocialIssue{"og:url", "URL must be absolute"})
+	}
+	if image := first(result.OpenGraph, "og:image"); image != "" && !isAbsoluteURL(image) {
+		result.Issues = append(result.Issues, SocialIssue{"og:image", "URL cannot be resolved against the page"})
+	}
+	return result, nil
+}
+
+func isAbsoluteURL(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,93 @@
+package parser_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+)
+
+func TestSocialAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		html            string
+		pageURL         string
+		expectedPreview parser.SocialPreview
+		expectedImages  []string
+		expectedIssues  []parser.SocialIssue
+	}{
+		{
+			name: "should extract Open Graph and resolve relative images",
+			html: `<html><head>
+				<meta property="og:title" content="Sofa Anton">
+				<meta property="og:type" content="product">
+				<meta property="OG:Image" content="/images/anton.jpg">
+				<meta property="og:image" content="https://cdn.home24.de/anton-2.jpg">
+				<meta property="og:url" content="https://www.home24.de/anton">
+				<meta property="og:site_name" content="home24">
+				<meta name="twitter:card" content="summary_large_image">
+				<meta name="twitter:description" content="Ein bequemes Sofa">
+			</head></html>`,
+			pageURL: "https://www.home24.de/moebel/anton",
+			expectedPreview: parser.SocialPreview{
+				Title:       "Sofa Anton",
+				Description: "Ein bequemes Sofa",
+				Image:       "https://www.home24.de/images/anton.jpg",
+				URL:         "https://www.home24.de/anton",
+				Type:        "product",
+				SiteName:    "home24",
+				Card:        "summary_large_image",
+			},
+			expectedImages: []string{"https://www.home24.de/images/anton.jpg", "https://cdn.home24.de/anton-2.jpg"},
+			expectedIssues: []parser.SocialIssue{},
+		},
+		{
+			name: "should fall back to Twitter cards and report missing properties",
+			html: `<html><head>
+				<meta name="twitter:title" content="Sofa Anton">
+				<meta property="twitter:image" content="anton.jpg">
+				<meta property="og:url" content="/anton">
+			</head></html>`,
+			pageURL: "https://www.home24.de/moebel/",
+			expectedPreview: parser.SocialPreview{
+				Title: "Sofa Anton",
+				Image: "https://www.home24.de/moebel/anton.jpg",
+				URL:   "/anton",
+			},
+			expectedIssues: []parser.SocialIssue{
+				{Property: "og:title", Message: "required property is missing"},
+				{Property: "og:type", Message: "required property is missing"},
+				{Property: "og:image", Message: "required property is missing"},
+				{Property: "og:url", Message: "URL must be absolute"},
+			},
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString(tcase.html, tcase.pageURL)
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			result, err := parser.NewSocialAnalyzer().Analyze(context.Background(), doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			social := result.(parser.SocialResult)
+			if social.Preview != tcase.expectedPreview {
+				t.Errorf("Expected preview %+v, got %+v", tcase.expectedPreview, social.Preview)
+			}
+			if fmt.Sprint(social.OpenGraph["og:image"]) != fmt.Sprint(tcase.expectedImages) {
+				t.Errorf("Expected images %v, got %v", tcase.expectedImages, social.OpenGraph["og:image"])
+			}
+			if fmt.Sprint(social.Issues) != fmt.Sprint(tcase.expectedIssues) {
+				t.Errorf("Expected issues %v, got %v", tcase.expectedIssues, social.Issues)
+			}
+		})
+	}
+}
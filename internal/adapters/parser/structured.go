@@ -0,0 +1,330 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Formats structured data is embedded in.
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+)
+
+// DefaultRequiredProperties are the properties checked when none are configured,
+// the ones rich results of products and breadcrumbs need.
+var DefaultRequiredProperties = map[string][]string{
+	"Product":        {"name", "offers"},
+	"Offer":          {"price", "priceCurrency"},
+	"BreadcrumbList": {"itemListElement"},
+	"ListItem":       {"position"},
+}
+
+// schemaPrefixes are stripped from types and properties, so that items are
+// described the same way in every format.
+var schemaPrefixes = []string{"https://schema.org/", "http://schema.org/", "schema:"}
+
+type StructuredDataConfig struct {
+	// RequiredProperties lists the properties items of a schema.org type, such
+	// as "Product", must have. Types that are not listed are not checked.
+	// Nil uses DefaultRequiredProperties.
+	RequiredProperties map[string][]string
+}
+
+// StructuredDataResult holds the structured data items of a page. Items found
+// in every format are normalized into the same tree.
+type StructuredDataResult struct {
+	Items  []StructuredItem      `json:"items"`
+	Errors []StructuredDataError `json:"errors"`
+	Issues []StructuredDataIssue `json:"issues"`
+}
+
+// Apply implements the Result interface.
+func (r StructuredDataResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis("structuredData", r)
+}
+
+// StructuredItem is a schema.org item. Every property can have several values,
+// which are strings, numbers, booleans or nested items.
+type StructuredItem struct {
+	// Format is only set on top level items.
+	Format     string           `json:"format,omitempty"`
+	Types      []string         `json:"types"`
+	ID         string           `json:"id,omitempty"`
+	Properties map[string][]any `json:"properties"`
+}
+
+// StructuredDataError is a block of structured data that could not be parsed.
+type StructuredDataError struct {
+	Format  string `json:"format"`
+	Message string `json:"message"`
+}
+
+// StructuredDataIssue is a required property an item does not have.
+type StructuredDataIssue struct {
+	Format   string `json:"format"`
+	Type     string `json:"type"`
+	Property string `json:"property"`
+	Message  string `json:"message"`
+}
+
+// StructuredDataAnalyzer extracts the JSON-LD, Microdata and RDFa items of a
+// page, and checks the required properties of the configured types.
+type StructuredDataAnalyzer struct {
+	cfg StructuredDataConfig
+}
+
+func NewStructuredDataAnalyzer(cfg StructuredDataConfig) *StructuredDataAnalyzer {
+	if cfg.RequiredProperties == nil {
+		cfg.RequiredProperties = DefaultRequiredProperties
+	}
+	return &StructuredDataAnalyzer{cfg: cfg}
+}
+
+// Name implements the Analyzer interface.
+func (a *StructuredDataAnalyzer) Name() string {
+	return "structuredData"
+}
+
+// Analyze implements the Analyzer interface.
+func (a *StructuredDataAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	root := document.Root()
+	if root == nil {
+		return nil, ErrDocumentNotLoaded
+	}
+	result := StructuredDataResult{
+		Items:  []StructuredItem{},
+		Errors: []StructuredDataError{},
+		Issues: []StructuredDataIssue{},
+	}
+
+	scripts, err := htmlquery.QueryAll(root, "//script[lower-case(normalize-space(@type))='application/ld+json']")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+	}
+	for i, script := range scripts {
+		items, err := parseJSONLD(htmlquery.InnerText(script))
+		if err != nil {
+			result.Errors = append(result.Errors, StructuredDataError{FormatJSONLD, fmt.Sprintf("block %d: %v", i+1, err)})
+			continue
+		}
+		result.Items = append(result.Items, items...)
+	}
+	for _, vocabulary := range []itemAttributes{microdataAttributes, rdfaAttributes} {
+		result.Items = append(result.Items, extractItems(root, document.URL(), vocabulary)...)
+	}
+
+	for _, item := range result.Items {
+		a.check(item, item.Format, &result.Issues)
+	}
+	return result, nil
+}
+
+// check appends an issue for every required property item, or the items
+// nested in it, does not have.
+func (a *StructuredDataAnalyzer) check(item StructuredItem, format string, issues *[]StructuredDataIssue) {
+	for _, t := range item.Types {
+		for _, property := range a.cfg.RequiredProperties[t] {
+			if len(item.Properties[property]) == 0 {
+				*issues = append(*issues, StructuredDataIssue{format, t, property, "required property is missing"})
+			}
+		}
+	}
+	for _, property := range slices.Sorted(maps.Keys(item.Properties)) {
+		for _, value := range item.Properties[property] {
+			if nested, ok := value.(StructuredItem); ok {
+				a.check(nested, format, issues)
+			}
+		}
+	}
+}
+
+// parseJSONLD parses a JSON-LD block, which holds an item, a list of items or
+// a @graph of items.
+func parseJSONLD(block string) ([]StructuredItem, error) {
+	var data any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(block)), &data); err != nil {
+		return nil, err
+	}
+	var nodes []any
+	switch data := data.(type) {
+	case []any:
+		nodes = data
+	case map[string]any:
+		if graph, ok := data["@graph"].([]any); ok {
+			nodes = graph
+		} else {
+			nodes = []any{data}
+		}
+	default:
+		return nil, fmt.Errorf("expected an object or an array")
+	}
+	items := []StructuredItem{}
+	for _, node := range nodes {
+		if object, ok := node.(map[string]any); ok {
+			item := jsonLDItem(object)
+			item.Format = FormatJSONLD
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func jsonLDItem(object map[string]any) StructuredItem {
+	item := StructuredItem{Types: []string{}, Properties: map[string][]any{}}
+	for key, value := range object {
+		switch key {
+		case "@type":
+			for _, t := range jsonLDValues(value) {
+				if t, ok := t.(string); ok {
+					item.Types = append(item.Types, trimSchemaPrefix(t))
+				}
+			}
+		case "@id":
+			item.ID, _ = value.(string)
+		default:
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+			for _, v := range jsonLDValues(value) {
+				if nested, ok := v.(map[string]any); ok {
+					v = jsonLDItem(nested)
+				}
+				item.Properties[trimSchemaPrefix(key)] = append(item.Properties[trimSchemaPrefix(key)], v)
+			}
+		}
+	}
+	return item
+}
+
+func jsonLDValues(value any) []any {
+	if values, ok := value.([]any); ok {
+		return values
+	}
+	return []any{value}
+}
+
+// itemAttributes are the attributes Microdata and RDFa describe items with.
+type itemAttributes struct {
+	format string
+	// scope starts an item, and typ holds its types.
+	scope    string
+	typ      string
+	id       string
+	property string
+}
+
+var microdataAttributes = itemAttributes{FormatMicrodata, "itemscope", "itemtype", "itemid", "itemprop"}
+var rdfaAttributes = itemAttributes{FormatRDFa, "typeof", "typeof", "resource", "property"}
+
+// extractItems returns the top level items described with attrs, those that are
+// not the property of another item.
+func extractItems(root *html.Node, pageURL string, attrs itemAttributes) []StructuredItem {
+	items := []StructuredItem{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasAttr(n, attrs.scope) && !hasAttr(n, attrs.property) {
+			item := elementItem(n, pageURL, attrs)
+			item.Format = attrs.format
+			items = append(items, item)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return items
+}
+
+func elementItem(n *html.Node, pageURL string, attrs itemAttributes) StructuredItem {
+	item := StructuredItem{
+		Types:      []string{},
+		ID:         htmlquery.SelectAttr(n, attrs.id),
+		Properties: map[string][]any{},
+	}
+	for _, t := range strings.Fields(htmlquery.SelectAttr(n, attrs.typ)) {
+		item.Types = append(item.Types, trimSchemaPrefix(t))
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(htmlquery.SelectAttr(c, attrs.property))
+			if len(names) > 0 {
+				var value any
+				if hasAttr(c, attrs.scope) {
+					value = elementItem(c, pageURL, attrs)
+				} else {
+					value = elementValue(c, pageURL)
+				}
+				for _, name := range names {
+					name = trimSchemaPrefix(name)
+					item.Properties[name] = append(item.Properties[name], value)
+				}
+			}
+			// The properties of nested items belong to them.
+			if !hasAttr(c, attrs.scope) {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return item
+}
+
+// elementValue returns the value of a property element, which depends on the
+// element it is set on.
+func elementValue(n *html.Node, pageURL string) string {
+	if content, ok := attr(n, "content"); ok {
+		return strings.TrimSpace(content)
+	}
+	switch n.Data {
+	case "a", "area", "link":
+		return resolveURL(pageURL, htmlquery.SelectAttr(n, "href"))
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		return resolveURL(pageURL, htmlquery.SelectAttr(n, "src"))
+	case "object":
+		return resolveURL(pageURL, htmlquery.SelectAttr(n, "data"))
+	case "data", "meter":
+		return strings.TrimSpace(htmlquery.SelectAttr(n, "value"))
+	case "time":
+		if datetime, ok := attr(n, "datetime"); ok {
+			return strings.TrimSpace(datetime)
+		}
+	}
+	return strings.Join(strings.Fields(htmlquery.InnerText(n)), " ")
+}
+
+func trimSchemaPrefix(name string) string {
+	for _, prefix := range schemaPrefixes {
+		if trimmed, ok := strings.CutPrefix(name, prefix); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	_, ok := attr(n, name)
+	return ok
+}
+
+func attr(n *html.Node, name string) (string, bool) {
+	i := slices.IndexFunc(n.Attr, func(a html.Attribute) bool { return a.Key == name })
+	if i < 0 {
+		return "", false
+	}
+	return n.Attr[i].Val, true
+}
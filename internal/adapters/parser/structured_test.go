@@ -0,0 +1,120 @@
+package parser_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+)
+
+func TestStructuredDataAnalyzer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		html           string
+		cfg            parser.StructuredDataConfig
+		expectedItems  string
+		expectedErrors int
+		expectedIssues []parser.StructuredDataIssue
+	}{
+		{
+			name: "should extract JSON-LD items and graphs",
+			html: `<script type="application/ld+json">
+				{"@context":"https://schema.org","@type":"Product","name":"Sofa Anton",
+				 "offers":{"@type":"Offer","price":"499.00","priceCurrency":"EUR"}}
+			</script>
+			<script type="application/ld+json">
+				{"@context":"https://schema.org","@graph":[{"@type":"BreadcrumbList","itemListElement":[
+					{"@type":"ListItem","position":1,"name":"Möbel"},
+					{"@type":"ListItem","position":2,"name":"Sofas"}]}]}
+			</script>`,
+			expectedItems: `[{"format":"json-ld","types":["Product"],"properties":{"name":["Sofa Anton"],"offers":[{"types":["Offer"],"properties":{"price":["499.00"],"priceCurrency":["EUR"]}}]}},` +
+				`{"format":"json-ld","types":["BreadcrumbList"],"properties":{"itemListElement":[{"types":["ListItem"],"properties":{"name":["Möbel"],"position":[1]}},{"types":["ListItem"],"properties":{"name":["Sofas"],"position":[2]}}]}}]`,
+			expectedIssues: []parser.StructuredDataIssue{},
+		},
+		{
+			name: "should extract Microdata items",
+			html: `<div itemscope itemtype="https://schema.org/Product" itemid="#anton">
+				<h1 itemprop="name">Sofa <b>Anton</b></h1>
+				<img itemprop="image" src="/anton.jpg">
+				<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+					<meta itemprop="priceCurrency" content="EUR">
+					<span itemprop="price">499.00</span>
+				</div>
+			</div>`,
+			expectedItems:  `[{"format":"microdata","types":["Product"],"id":"#anton","properties":{"image":["https://www.home24.de/anton.jpg"],"name":["Sofa Anton"],"offers":[{"types":["Offer"],"properties":{"price":["499.00"],"priceCurrency":["EUR"]}}]}}]`,
+			expectedIssues: []parser.StructuredDataIssue{},
+		},
+		{
+			name: "should extract RDFa items",
+			html: `<ol vocab="https://schema.org/" typeof="BreadcrumbList">
+				<li property="itemListElement" typeof="ListItem">
+					<a property="item" href="/moebel"><span property="name">Möbel</span></a>
+					<meta property="position" content="1">
+				</li>
+			</ol>`,
+			expectedItems:  `[{"format":"rdfa","types":["BreadcrumbList"],"properties":{"itemListElement":[{"types":["ListItem"],"properties":{"item":["https://www.home24.de/moebel"],"name":["Möbel"],"position":["1"]}}]}}]`,
+			expectedIssues: []parser.StructuredDataIssue{},
+		},
+		{
+			name: "should report malformed JSON-LD and missing properties",
+			html: `<script type="application/ld+json">{"@type":"Product",}</script>
+			<script type="application/ld+json">{"@type":"Product","offers":{"@type":"Offer","price":"499.00"}}</script>`,
+			expectedItems:  `[{"format":"json-ld","types":["Product"],"properties":{"offers":[{"types":["Offer"],"properties":{"price":["499.00"]}}]}}]`,
+			expectedErrors: 1,
+			expectedIssues: []parser.StructuredDataIssue{
+				{Format: "json-ld", Type: "Product", Property: "name", Message: "required property is missing"},
+				{Format: "json-ld", Type: "Offer", Property: "priceCurrency", Message: "required property is missing"},
+			},
+		},
+		{
+			name: "should check the configured types",
+			html: `<script type="application/ld+json">{"@type":"Organization","name":"home24"}</script>`,
+			cfg: parser.StructuredDataConfig{RequiredProperties: map[string][]string{
+				"Organization": {"name", "logo"},
+			}},
+			expectedItems: `[{"format":"json-ld","types":["Organization"],"properties":{"name":["home24"]}}]`,
+			expectedIssues: []parser.StructuredDataIssue{
+				{Format: "json-ld", Type: "Organization", Property: "logo", Message: "required property is missing"},
+			},
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString("<html><body>"+tcase.html+"</body></html>", "https://www.home24.de/sofas")
+			if err != nil {
+				t.Fatalf("Failed to load document: %v", err)
+			}
+
+			result, err := parser.NewStructuredDataAnalyzer(tcase.cfg).Analyze(context.Background(), doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			structured := result.(parser.StructuredDataResult)
+			items, err := json.Marshal(structured.Items)
+			if err != nil {
+				t.Fatalf("Could not encode items: %v", err)
+			}
+			if string(items) != tcase.expectedItems {
+				t.Errorf("Expected items %v, got %v", tcase.expectedItems, string(items))
+			}
+			if len(structured.Errors) != tcase.expectedErrors {
+				t.Errorf("Expected %v errors, got %v", tcase.expectedErrors, structured.Errors)
+			}
+			for _, e := range structured.Errors {
+				if e.Format != parser.FormatJSONLD || !strings.HasPrefix(e.Message, "block 1:") {
+					t.Errorf("Expected the malformed block to be reported, got %+v", e)
+				}
+			}
+			if fmt.Sprint(structured.Issues) != fmt.Sprint(tcase.expectedIssues) {
+				t.Errorf("Expected issues %v, got %v", tcase.expectedIssues, structured.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// tlsInfo describes the connection of state, or returns nil when the response
+// was not served over TLS. Certificates that expired, which servers can send
+// alongside a valid chain, or that expire in less than
+// Config.CertificateExpiryWarning from now are warned about.
+func (p *WebPageParser) tlsInfo(state *tls.ConnectionState, now time.Time) *model.TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &model.TLSInfo{
+		Version:      tls.VersionName(state.Version),
+		CipherSuite:  tls.CipherSuiteName(state.CipherSuite),
+		Certificates: []model.Certificate{},
+	}
+	for _, cert := range state.PeerCertificates {
+		sans := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		remaining := cert.NotAfter.Sub(now)
+		days := max(int(remaining/(24*time.Hour)), 0)
+		info.Certificates = append(info.Certificates, model.Certificate{
+			Subject:       cert.Subject.String(),
+			Issuer:        cert.Issuer.String(),
+			SANs:          sans,
+			NotBefore:     cert.NotBefore,
+			NotAfter:      cert.NotAfter,
+			DaysRemaining: days,
+		})
+		switch {
+		case remaining <= 0:
+			info.Warnings = append(info.Warnings, fmt.Sprintf("certificate %q expired %d days ago", cert.Subject.String(), int(-remaining/(24*time.Hour))))
+		case remaining < p.cfg.CertificateExpiryWarning:
+			info.Warnings = append(info.Warnings, fmt.Sprintf("certificate %q expires in %d days", cert.Subject.String(), days))
+		}
+	}
+	return info
+}
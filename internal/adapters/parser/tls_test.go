@@ -0,0 +1,146 @@
+package parser_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+func TestDownloadDocument_TLS(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+	}))
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{RootCAs: roots})
+
+	tests := []struct {
+		name             string
+		expiryWarning    time.Duration
+		expectedWarnings int
+	}{
+		{
+			name: "should not warn about certificates that are far from expiring",
+		},
+		{
+			name:             "should warn about certificates that expire soon",
+			expiryWarning:    time.Until(srv.Certificate().NotAfter) + 24*time.Hour,
+			expectedWarnings: 1,
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			prsr := parser.NewWebPageParser(pageFetcher, parser.Config{CertificateExpiryWarning: tcase.expiryWarning})
+
+			doc, err := prsr.DownloadDocument(context.Background(), srv.URL, model.FetchOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			info := doc.TLS()
+			if info == nil {
+				t.Fatalf("Expected TLS info")
+			}
+			if !strings.HasPrefix(info.Version, "TLS 1.") || info.CipherSuite == "" {
+				t.Errorf("Expected the version and cipher suite, got %+v", info)
+			}
+			if len(info.Certificates) != 1 {
+				t.Fatalf("Expected the certificate of the server, got %+v", info.Certificates)
+			}
+			cert := info.Certificates[0]
+			if !slices.Contains(cert.SANs, "example.com") || !slices.Contains(cert.SANs, "127.0.0.1") {
+				t.Errorf("Expected the DNS names and addresses, got %v", cert.SANs)
+			}
+			if !cert.NotAfter.Equal(srv.Certificate().NotAfter) || cert.DaysRemaining <= 0 {
+				t.Errorf("Expected the expiry of the certificate, got %v (%v days)", cert.NotAfter, cert.DaysRemaining)
+			}
+			if cert.Subject == "" || cert.Issuer == "" {
+				t.Errorf("Expected subject and issuer, got %+v", cert)
+			}
+			if len(info.Warnings) != tcase.expectedWarnings {
+				t.Errorf("Expected %v warnings, got %v", tcase.expectedWarnings, info.Warnings)
+			}
+		})
+	}
+
+	t.Run("should warn about certificates that expired", func(t *testing.T) {
+		// Servers can send expired certificates, such as an old intermediate,
+		// along with the chain that is verified.
+		expired := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+		}))
+		defer expired.Close()
+		expired.TLS.Certificates[0].Certificate = append(expired.TLS.Certificates[0].Certificate, newExpiredCertificate(t, 10*24*time.Hour))
+		roots := x509.NewCertPool()
+		roots.AddCert(expired.Certificate())
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{RootCAs: roots}), parser.Config{})
+
+		doc, err := prsr.DownloadDocument(context.Background(), expired.URL, model.FetchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		info := doc.TLS()
+		if len(info.Certificates) != 2 || info.Certificates[1].DaysRemaining != 0 {
+			t.Fatalf("Expected the expired certificate to have no days remaining, got %+v", info.Certificates)
+		}
+		expected := []string{`certificate "CN=Expired Intermediate" expired 10 days ago`}
+		if !slices.Equal(info.Warnings, expected) {
+			t.Errorf("Expected warnings %q, got %q", expected, info.Warnings)
+		}
+	})
+
+	t.Run("should not have TLS info for pages served over HTTP", func(t *testing.T) {
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+		}))
+		defer plain.Close()
+
+		doc, err := parser.NewWebPageParser(pageFetcher, parser.Config{}).DownloadDocument(context.Background(), plain.URL, model.FetchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if doc.TLS() != nil {
+			t.Fatalf("Expected no TLS info, got %+v", doc.TLS())
+		}
+	})
+}
+
+// newExpiredCertificate returns a self-signed certificate that expired age ago,
+// plus an hour, so that it expired age ago in whole days.
+func newExpiredCertificate(t *testing.T, age time.Duration) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	notAfter := time.Now().Add(-age - time.Hour)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Expired Intermediate"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return der
+}
@@ -1,11 +1,21 @@
 package parser
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
 	"github.com/antchfx/htmlquery"
 	"github.com/antchfx/xpath"
 	"golang.org/x/net/html"
@@ -14,137 +24,242 @@ import (
 var ErrCouldNotLoadDocument error = errors.New("could not load document")
 var ErrDocumentNotLoaded error = errors.New("document has not been loaded")
 var ErrFailedQuerying error = errors.New("failed to query document")
-var ErrElementNotFound error = errors.New("could not find element")
-var ErrNoVersionFound error = errors.New("could not find document version")
+var ErrElementNotFound error = fmt.Errorf("could not find element: %w", ports.ErrNotFound)
+var ErrNoVersionFound error = fmt.Errorf("could not find document version: %w", ports.ErrNotStated)
 
 var regexHostnameURL = regexp.MustCompile(`^(https?:\/\/)?([^/?#:]+)`)
 
+const defaultMaxBodySize = 10 << 20
+const defaultCertificateExpiryWarning = 30 * 24 * time.Hour
+
+// htmlContentTypes are the media types of the documents that can be parsed.
+var htmlContentTypes = []string{"text/html", "application/xhtml+xml"}
+
+type Config struct {
+	// MaxBodySize is the maximum size in bytes of a downloaded document.
+	MaxBodySize int64
+	// CertificateExpiryWarning is how long before a certificate expires the
+	// report starts warning about it.
+	CertificateExpiryWarning time.Duration
+}
+
+// WebPageParser holds no per-document state, so a single instance can be
+// shared by concurrent requests. Every download returns its own WebPageDocument.
 type WebPageParser struct {
-	document    *html.Node
-	documentURL string
+	fetcher ports.Fetcher
+	cfg     Config
 }
 
-func NewWebPageParser() *WebPageParser {
+// NewWebPageParser returns a parser that downloads documents with fetcher.
+func NewWebPageParser(fetcher ports.Fetcher, cfg Config) *WebPageParser {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBodySize
+	}
+	if cfg.CertificateExpiryWarning <= 0 {
+		cfg.CertificateExpiryWarning = defaultCertificateExpiryWarning
+	}
 	return &WebPageParser{
-		documentURL: "",
+		fetcher: fetcher,
+		cfg:     cfg,
 	}
 }
 
-// DownloadPage implements the DocumentParser interface.
-func (p *WebPageParser) DownloadDocument(location string) error {
-	var err error
-	p.documentURL = location
-	p.document, err = htmlquery.LoadURL(location)
-	if err != nil {
-		err = fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
-	}
-	return err
+// WebPageDocument is a parsed HTML document. It is never modified after being
+// created, which makes it safe to query from several goroutines.
+type WebPageDocument struct {
+	document    *html.Node
+	documentURL string
+	redirects   []model.Redirect
+	response    *model.ResponseInfo
+	header      http.Header
+	tls         *model.TLSInfo
+	charset     string
+	// links are extracted once, when the document is parsed, and shared by all
+	// the analyzers that need them.
+	links []string
+	// fetchOptions are the ones the document was downloaded with, without the
+	// credentials when it was redirected to another host.
+	fetchOptions model.FetchOptions
 }
 
-func (p *WebPageParser) FromString(content string, url string) error {
-	p.documentURL = url
-	var err error
-	p.document, err = htmlquery.Parse(strings.NewReader(content))
-	if err != nil {
-		err = fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
+// DownloadDocument implements the DocumentParser interface.
+// The download is aborted as soon as ctx is done. Only HTML documents of at
+// most Config.MaxBodySize bytes are downloaded. Redirects are followed, and
+// the links of the document are resolved against the URL it ends up at. The
+// credentials of opts were meant for the host of location, so they are dropped
+// when the document ends up on another host.
+func (p *WebPageParser) DownloadDocument(ctx context.Context, location string, opts model.FetchOptions) (ports.Document, error) {
+	start := time.Now()
+	res, err := p.fetcher.Fetch(ctx, http.MethodGet, location, opts)
+	if errors.Is(err, ports.ErrInvalidFetchOptions) || errors.Is(err, ports.ErrForbiddenURL) ||
+		errors.Is(err, ports.ErrTooManyRedirects) {
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
 	}
-	return err
-
-}
-
-// GetDocumentVersion implements the DocumentParser interface.
-func (p *WebPageParser) GetDocumentVersion() (string, error) {
-	if p.document == nil {
-		return "", ErrDocumentNotLoaded
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", ErrCouldNotLoadDocument, ports.ErrUnreachableHost, err)
 	}
-	doctypeNode := p.document.FirstChild
-	if doctypeNode.Type != html.DoctypeNode {
-		return "", ErrNoVersionFound
+	defer res.Body.Close()
+	timeToFirstByte := time.Since(start)
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: %w: %v", ErrCouldNotLoadDocument, ports.ErrUpstreamServerError, res.Status)
 	}
-
-	numOfAttributes := len(doctypeNode.Attr)
-	isVersionFive := numOfAttributes == 0
-	if isVersionFive {
-		return "5", nil
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: %w: %v", ErrCouldNotLoadDocument, ports.ErrUpstreamClientError, res.Status)
 	}
-
-	rx, err := regexp.Compile(`[Hh][Tt][Mm][Ll] ([0-9](\.[0-9][0-9]*)*)`)
+	body, err := p.readBody(res)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
 	}
-	rxResult := rx.FindAllStringSubmatch(doctypeNode.Attr[0].Val, -1)
-	if len(rxResult) == 0 {
-		return "", ErrNoVersionFound
+	if requested, err := url.Parse(location); err != nil || requested.Host != res.Request.URL.Host {
+		opts = opts.WithoutCredentials()
 	}
-
-	version := rxResult[0][1]
-
-	return version, nil
+	document, err := p.parse(body, res.Header.Get("Content-Type"), res.Request.URL.String(), opts)
+	if err != nil {
+		return nil, err
+	}
+	document.redirects = redirects(res)
+	document.header = res.Header
+	document.tls = p.tlsInfo(res.TLS, time.Now())
+	document.response = responseInfo(res, int64(len(body)), timeToFirstByte, time.Since(start))
+	return document, nil
 }
 
-// GetTitle implements the DocumentParser interface.
-func (p *WebPageParser) GetTitle() (string, error) {
-	doc := p.document
-	if doc == nil {
-		return "", ErrDocumentNotLoaded
-	}
-	title, err := htmlquery.Query(doc, "//title")
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrFailedQuerying, err)
+func responseInfo(res *http.Response, bodySize int64, timeToFirstByte time.Duration, totalTime time.Duration) *model.ResponseInfo {
+	info := &model.ResponseInfo{
+		StatusCode:      res.StatusCode,
+		Protocol:        res.Proto,
+		TimeToFirstByte: timeToFirstByte,
+		TotalTime:       totalTime,
+		ContentLength:   res.ContentLength,
+		BodySize:        bodySize,
+		Compression:     res.Header.Get("Content-Encoding"),
+		Server:          res.Header.Get("Server"),
+		CacheControl:    res.Header.Get("Cache-Control"),
+		LastModified:    res.Header.Get("Last-Modified"),
+		ETag:            res.Header.Get("ETag"),
 	}
-	if title == nil {
-		return "", ErrElementNotFound
+	// net/http removes the encoding of the responses it decompresses itself,
+	// which it only asks for with gzip.
+	if res.Uncompressed {
+		info.Compression = "gzip"
 	}
-	txtNode := title.FirstChild
-	if txtNode == nil {
-		return "", nil
-	} else {
-		return txtNode.Data, nil
+	return info
+}
+
+// redirects returns the redirects that led to res, which net/http keeps as a
+// chain going back from the last request.
+func redirects(res *http.Response) []model.Redirect {
+	var chain []model.Redirect
+	for r := res.Request.Response; r != nil; r = r.Request.Response {
+		chain = append(chain, model.Redirect{
+			URL:        r.Request.URL.String(),
+			StatusCode: r.StatusCode,
+			Location:   r.Header.Get("Location"),
+		})
 	}
+	slices.Reverse(chain)
+	return chain
 }
 
-// GetExternalLinkCount implements the DocumentParser interface.
-func (p *WebPageParser) GetExternalLinkCount() (int, error) {
-	if p.document == nil {
-		return 0, ErrDocumentNotLoaded
+// readBody reads the body of an HTML response, without reading more than
+// Config.MaxBodySize bytes. Responses without a content type are sniffed.
+func (p *WebPageParser) readBody(res *http.Response) ([]byte, error) {
+	if res.ContentLength > p.cfg.MaxBodySize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d are allowed", ports.ErrDocumentTooLarge, res.ContentLength, p.cfg.MaxBodySize)
 	}
-	count := 0
-	links, err := getAllLinks(p.document)
+	contentType := res.Header.Get("Content-Type")
+	if contentType != "" && !isHTML(contentType) {
+		return nil, fmt.Errorf("%w: content type %q", ports.ErrNotHTML, contentType)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, p.cfg.MaxBodySize+1))
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	for _, link := range links {
-		isInternal, err := isLinkURLInternal(p.documentURL, link)
-		if err != nil {
-			return 0, err
-		}
-		if !isInternal {
-			count++
-		}
+	if int64(len(body)) > p.cfg.MaxBodySize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ports.ErrDocumentTooLarge, p.cfg.MaxBodySize)
+	}
+	if contentType == "" && !isHTML(http.DetectContentType(body)) {
+		return nil, fmt.Errorf("%w: content type %q", ports.ErrNotHTML, http.DetectContentType(body))
 	}
-	return count, nil
+	return body, nil
 }
 
-// GetInternalLinkCount implements the DocumentParser interface.
-func (p *WebPageParser) GetInternalLinkCount() (int, error) {
-	if p.document == nil {
-		return 0, ErrDocumentNotLoaded
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && slices.Contains(htmlContentTypes, mediaType)
+}
+
+// FromString parses a document that has already been read, such as a local
+// file. Its encoding is detected from its content alone.
+func (p *WebPageParser) FromString(content string, url string) (*WebPageDocument, error) {
+	return p.parse([]byte(content), "", url, model.FetchOptions{})
+}
+
+func (p *WebPageParser) parse(content []byte, contentType string, url string, opts model.FetchOptions) (*WebPageDocument, error) {
+	content, charset, err := decode(content, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
 	}
-	count := 0
-	links, err := getAllLinks(p.document)
+	document, err := htmlquery.Parse(bytes.NewReader(content))
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
 	}
-	for _, link := range links {
-		isInternal, err := isLinkURLInternal(p.documentURL, link)
-		if err != nil {
-			return 0, err
-		}
-		if isInternal {
-			count++
-		}
+	links, err := getAllLinks(document)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotLoadDocument, err)
 	}
-	return count, nil
+	return &WebPageDocument{
+		document:     document,
+		documentURL:  url,
+		charset:      charset,
+		links:        links,
+		fetchOptions: opts,
+	}, nil
+}
+
+// URL implements the Document interface.
+func (d *WebPageDocument) URL() string {
+	return d.documentURL
+}
+
+// Redirects implements the Document interface.
+func (d *WebPageDocument) Redirects() []model.Redirect {
+	return d.redirects
+}
+
+// ResponseInfo implements the Document interface.
+func (d *WebPageDocument) ResponseInfo() *model.ResponseInfo {
+	return d.response
+}
+
+// TLS implements the Document interface.
+func (d *WebPageDocument) TLS() *model.TLSInfo {
+	return d.tls
+}
+
+// Header implements the Document interface.
+func (d *WebPageDocument) Header() http.Header {
+	return d.header
+}
+
+// Root implements the Document interface.
+func (d *WebPageDocument) Root() *html.Node {
+	return d.document
+}
+
+// Charset implements the Document interface.
+func (d *WebPageDocument) Charset() string {
+	return d.charset
+}
+
+// Links implements the Document interface.
+func (d *WebPageDocument) Links() []string {
+	return d.links
+}
+
+// FetchOptions implements the Document interface.
+func (d *WebPageDocument) FetchOptions() model.FetchOptions {
+	return d.fetchOptions
 }
 
 func getAllLinks(doc *html.Node) ([]string, error) {
@@ -164,6 +279,33 @@ func getAllLinks(doc *html.Node) ([]string, error) {
 	return links, nil
 }
 
+// resolveLinks turns every link into an absolute http(s) URL, dropping
+// duplicates, in-page anchors and links to other schemes such as mailto: or tel:.
+func resolveLinks(base *url.URL, links []string) []string {
+	resolved := []string{}
+	seen := map[string]bool{}
+	for _, link := range links {
+		link = strings.TrimSpace(link)
+		if strings.HasPrefix(link, "#") {
+			continue
+		}
+		ref, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		abs := base.ResolveReference(ref)
+		if abs.Scheme != "http" && abs.Scheme != "https" {
+			continue
+		}
+		abs.Fragment = ""
+		if !seen[abs.String()] {
+			seen[abs.String()] = true
+			resolved = append(resolved, abs.String())
+		}
+	}
+	return resolved
+}
+
 func isLinkURLInternal(pageURL string, linkURL string) (bool, error) {
 	if len(linkURL) > 0 && linkURL[0] == '/' {
 		return true, nil
@@ -172,6 +314,11 @@ func isLinkURLInternal(pageURL string, linkURL string) (bool, error) {
 	if err != nil {
 		return false, nil
 	}
+	if pageURL == "" {
+		// Documents that were not downloaded, such as local files, have no
+		// hostname of their own, so every absolute link points elsewhere.
+		return false, nil
+	}
 	pageHostname, err := getHostname(pageURL)
 	if err != nil {
 		return false, fmt.Errorf("the website's URL is not valid: %w", err)
@@ -179,83 +326,13 @@ func isLinkURLInternal(pageURL string, linkURL string) (bool, error) {
 	return linkHostname == pageHostname, nil
 }
 
-// GetContainsLogin implements the DocumentParser interface.
-func (p *WebPageParser) GetContainsLogin() (bool, error) {
-	doc := p.document
-	if doc == nil {
-		return false, ErrDocumentNotLoaded
-	}
-	forms, err := htmlquery.QueryAll(doc, "//form")
-	if err != nil {
-		return false, err
-	}
-
-	for _, f := range forms {
-		inputs, err := htmlquery.QueryAll(f, "//input")
-		hasUserInput, hasPasswordInput := false, false
-		if err != nil {
-			return false, fmt.Errorf("could not retrieve login: %w", err)
-		}
-		for _, i := range inputs {
-			for _, attr := range i.Attr {
-				if attr.Key == "type" {
-					if attr.Val == "text" || attr.Val == "email" {
-						hasUserInput = true
-					} else if attr.Val == "password" {
-						hasPasswordInput = true
-					}
-				}
-			}
-		}
-		if hasUserInput && hasPasswordInput {
-			return true, nil
-		}
-	}
-	return false, nil // False for now
-}
-
-// GetHeaderOneCount implements the DocumentParser interface.
-func (p *WebPageParser) GetHeaderOneCount() (int, error) {
-	count, err := p.getElementCount("//h1")
-	return count, err
-}
-
-// GetHeaderTwoCount implements the DocumentParser interface.
-func (p *WebPageParser) GetHeaderTwoCount() (int, error) {
-	count, err := p.getElementCount("//h2")
-	return count, err
-}
-
-// GetHeaderThreeCount implements the DocumentParser interface.
-func (p *WebPageParser) GetHeaderThreeCount() (int, error) {
-	count, err := p.getElementCount("//h3")
-	return count, err
-}
-
-// GetHeaderFourCount implements the DocumentParser interface.
-func (p *WebPageParser) GetHeaderFourCount() (int, error) {
-	count, err := p.getElementCount("//h4")
-	return count, err
-}
-
-func (p *WebPageParser) GetHeaderFiveCount() (int, error) {
-	count, err := p.getElementCount("//h5")
-	return count, err
-}
-
-func (p *WebPageParser) GetHeaderSixCount() (int, error) {
-	count, err := p.getElementCount("//h6")
-	return count, err
-}
-
-func (p *WebPageParser) getElementCount(element string) (int, error) {
+func countElements(doc *html.Node, element string) (int, error) {
 
 	expr := fmt.Sprintf("count(%v)", element)
 	comp, err := xpath.Compile(expr)
 	if err != nil {
 		return 0, err
 	}
-	doc := p.document
 	if doc == nil {
 		return 0, ErrDocumentNotLoaded
 	}
@@ -1,14 +1,24 @@
 package parser_test
 
 import (
+	"compress/gzip"
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
 	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
 )
 
 func TestNewMyDocumentParser(t *testing.T) {
-	pr := parser.NewWebPageParser()
+	pr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
 
 	if pr == nil {
 		t.Fatal("NewMyDocumentParser() returned nil")
@@ -46,8 +56,8 @@ func TestFromString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			prs := parser.NewWebPageParser()
-			err := prs.FromString(tt.content, "")
+			prs := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+			_, err := prs.FromString(tt.content, "")
 
 			if tt.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -60,534 +70,393 @@ func TestFromString(t *testing.T) {
 	}
 }
 
-func TestGetDocumentVersion(t *testing.T) {
+func TestDownloadDocument(t *testing.T) {
 	t.Parallel()
-
-	// Test without loading document
-	t.Run("should return error when no document is loaded", func(t *testing.T) {
-		prsr := parser.NewWebPageParser()
-		_, err := prsr.GetDocumentVersion()
-		if err != parser.ErrDocumentNotLoaded {
-			t.Fatalf("Expected document not loaded error to be return: %v", err)
-		}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>Test</title></head></html>")
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/xhtml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+		fmt.Fprint(w, "<html><head><title>Test</title></head></html>")
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		fmt.Fprint(w, "<html><head><title>Test</title></head></html>")
 	})
+	mux.HandleFunc("/pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, "%PDF-1.4")
+	})
+	mux.HandleFunc("/untyped-pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		fmt.Fprint(w, "%PDF-1.4")
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html><body>%v</body></html>", strings.Repeat("a", 2048))
+	})
+	mux.HandleFunc("/large-chunked", func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before writing the whole body leaves out the Content-Length.
+		fmt.Fprint(w, "<html><body>")
+		w.(http.Flusher).Flush()
+		fmt.Fprintf(w, "%v</body></html>", strings.Repeat("a", 2048))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
 
-	t.Run("should return error when there is no doctype", func(t *testing.T) {
-		prsr := parser.NewWebPageParser()
-		err := prsr.FromString("<html><head><title></title></head></html>", "")
-		if err != nil {
-			t.Fatalf("Failed to load document: %v", err)
-		}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
 
-		_, err = prsr.GetDocumentVersion()
-		if err != parser.ErrNoVersionFound {
-			t.Fatalf("Expected error was not returned: %v", err)
-		}
+	tests := []struct {
+		name        string
+		location    string
+		expectedErr error
+	}{
+		{
+			name:     "page is downloaded",
+			location: srv.URL + "/ok",
+		},
+		{
+			name:        "page responds with client error",
+			location:    srv.URL + "/missing",
+			expectedErr: ports.ErrUpstreamClientError,
+		},
+		{
+			name:        "page responds with server error",
+			location:    srv.URL + "/broken",
+			expectedErr: ports.ErrUpstreamServerError,
+		},
+		{
+			name:     "XHTML page is downloaded",
+			location: srv.URL + "/xhtml",
+		},
+		{
+			name:     "page without content type is sniffed",
+			location: srv.URL + "/untyped",
+		},
+		{
+			name:        "page is not HTML",
+			location:    srv.URL + "/pdf",
+			expectedErr: ports.ErrNotHTML,
+		},
+		{
+			name:        "page without content type is not HTML",
+			location:    srv.URL + "/untyped-pdf",
+			expectedErr: ports.ErrNotHTML,
+		},
+		{
+			name:        "page is larger than allowed",
+			location:    srv.URL + "/large",
+			expectedErr: ports.ErrDocumentTooLarge,
+		},
+		{
+			name:        "page without content length is larger than allowed",
+			location:    srv.URL + "/large-chunked",
+			expectedErr: ports.ErrDocumentTooLarge,
+		},
+		{
+			name:        "host is unreachable",
+			location:    closed.URL,
+			expectedErr: ports.ErrUnreachableHost,
+		},
+	}
 
-	})
-	t.Run("should return correct html version", func(t *testing.T) {
-		tests := []struct {
-			name            string
-			html            string
-			expectedVersion string
-		}{
-			{
-				name:            "version 5",
-				html:            "<!DOCTYPE html><html><head><title>Test</title></head></html>",
-				expectedVersion: "5",
-			},
-			{
-				name:            "version 4.01",
-				html:            "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 4.01//EN\" \"http://www.w3.org/TR/html4/strict.dtd\"><html><head><title>Test</title></head></html>",
-				expectedVersion: "4.01",
-			},
-			{
-				name:            "version 3.2",
-				html:            "<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 3.2 Final//EN\"><html><head><title>Test</title></head></html>",
-				expectedVersion: "3.2",
-			},
-			{
-				name:            "version 2.0",
-				html:            "<!DOCTYPE html PUBLIC \"-//IETF//DTD HTML 2.0//EN\"><html><head><title>Test</title></head></html>",
-				expectedVersion: "2.0",
-			},
-		}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{MaxBodySize: 1024})
 
-		prsr := parser.NewWebPageParser()
-		for _, tcase := range tests {
-			t.Run(tcase.name, func(t *testing.T) {
-				err := prsr.FromString(tcase.html, "")
-				if err != nil {
-					t.Fatalf("Failed to load document: %v", err)
-				}
+			doc, err := prsr.DownloadDocument(context.Background(), tcase.location, model.FetchOptions{})
 
-				actual, err := prsr.GetDocumentVersion()
+			if tcase.expectedErr == nil {
 				if err != nil {
 					t.Fatalf("Unexpected error: %v", err)
 				}
-
-				if actual != tcase.expectedVersion {
-					t.Fatalf("Expected %v version and returned %v", tcase.expectedVersion, actual)
+				if title, _ := analyze[parser.Title](parser.NewTitleAnalyzer(), doc); title != "Test" {
+					t.Fatalf("Expected title %q, got %q", "Test", title)
 				}
-			})
-		}
-	})
+				return
+			}
+			if !errors.Is(err, tcase.expectedErr) || !errors.Is(err, parser.ErrCouldNotLoadDocument) {
+				t.Fatalf("Expected %v, got %v", tcase.expectedErr, err)
+			}
+		})
+	}
 }
 
-func TestGetTitle(t *testing.T) {
+func TestDownloadDocument_Charset(t *testing.T) {
 	t.Parallel()
-	t.Run("should return error when document is not loaded", func(t *testing.T) {
-
-		prsr := parser.NewWebPageParser()
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		expectedTitle   parser.Title
+		expectedCharset string
+	}{
+		{
+			name:            "charset of the content type",
+			contentType:     "text/html; charset=ISO-8859-1",
+			body:            "<html><head><title>Caf\xe9</title></head></html>",
+			expectedTitle:   "Café",
+			expectedCharset: "windows-1252",
+		},
+		{
+			name:            "meta charset",
+			contentType:     "text/html",
+			body:            "<html><head><meta charset=\"windows-1252\"><title>\x80 Preis</title></head></html>",
+			expectedTitle:   "€ Preis",
+			expectedCharset: "windows-1252",
+		},
+		{
+			name:            "meta http-equiv",
+			contentType:     "text/html",
+			body:            "<html><head><meta http-equiv=\"Content-Type\" content=\"text/html; charset=Shift_JIS\"><title>\x93\xfa\x96\x7b</title></head></html>",
+			expectedTitle:   "日本",
+			expectedCharset: "shift_jis",
+		},
+		{
+			name:            "content type takes precedence over meta",
+			contentType:     "text/html; charset=utf-8",
+			body:            "<html><head><meta charset=\"windows-1252\"><title>Café</title></head></html>",
+			expectedTitle:   "Café",
+			expectedCharset: "utf-8",
+		},
+		{
+			name:            "byte order mark takes precedence over content type",
+			contentType:     "text/html; charset=windows-1252",
+			body:            "\xef\xbb\xbf<html><head><title>Café</title></head></html>",
+			expectedTitle:   "Café",
+			expectedCharset: "utf-8",
+		},
+		{
+			name:            "undeclared UTF-8",
+			contentType:     "text/html",
+			body:            "<html><head><title>Café</title></head></html>",
+			expectedTitle:   "Café",
+			expectedCharset: "utf-8",
+		},
+	}
 
-		title, err := prsr.GetTitle()
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tcase.contentType)
+				fmt.Fprint(w, tcase.body)
+			}))
+			defer srv.Close()
+			prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
 
-		if err == nil {
-			t.Error("Expected error but got none")
-		}
+			doc, err := prsr.DownloadDocument(context.Background(), srv.URL, model.FetchOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
-		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
-			t.Errorf("Expected ErrDocumentNotLoaded, got %v", err)
-		}
+			if title, _ := analyze[parser.Title](parser.NewTitleAnalyzer(), doc); title != tcase.expectedTitle {
+				t.Errorf("Expected title %q, got %q", tcase.expectedTitle, title)
+			}
+			if doc.Charset() != tcase.expectedCharset {
+				t.Errorf("Expected charset %q, got %q", tcase.expectedCharset, doc.Charset())
+			}
+		})
+	}
 
-		if title != "" {
-			t.Errorf("Expected empty title, got %q", title)
-		}
-	})
-	t.Run("should return correct title", func(t *testing.T) {
-		tests := []struct {
-			name          string
-			html          string
-			expectedTitle string
-			expectError   bool
-			errorType     error
-		}{
-			{
-				name:          "valid title",
-				html:          "<html><head><title>Test Page</title></head></html>",
-				expectedTitle: "Test Page",
-				expectError:   false,
-			},
-			{
-				name:          "empty title",
-				html:          "<html><head><title></title></head></html>",
-				expectedTitle: "",
-				expectError:   false,
-			},
-			{
-				name:        "no title element",
-				html:        "<html><head></head></html>",
-				expectError: true,
-				errorType:   parser.ErrElementNotFound,
-			},
+	t.Run("should not keep the byte order mark", func(t *testing.T) {
+		doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString("\xef\xbb\xbf<!DOCTYPE html><html></html>", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
 		}
-		for _, tcase := range tests {
-			t.Run(tcase.name, func(t *testing.T) {
-				parser := parser.NewWebPageParser()
-				err := parser.FromString(tcase.html, "")
-				if err != nil {
-					t.Fatalf("Failed to load document: %v", err)
-				}
 
-				title, err := parser.GetTitle()
-
-				if tcase.expectError {
-					if err == nil {
-						t.Error("Expected error but got none")
-					}
-					if tcase.errorType != nil && !errors.Is(err, tcase.errorType) {
-						t.Errorf("Expected error type %v, got %v", tcase.errorType, err)
-					}
-				} else {
-					if err != nil {
-						t.Errorf("Unexpected error: %v", err)
-					}
-					if title != tcase.expectedTitle {
-						t.Errorf("Expected title %q, got %q", tcase.expectedTitle, title)
-					}
-				}
-			})
+		if version, err := analyze[parser.DocumentVersion](parser.NewDocumentVersionAnalyzer(), doc); version != "5" {
+			t.Fatalf("Expected the doctype to be found, got %q %v", version, err)
 		}
 	})
-
 }
 
-func TestGetExternalLinkCount(t *testing.T) {
+func TestDownloadDocument_Redirects(t *testing.T) {
 	t.Parallel()
-	t.Run("should return error when document is not loaded", func(t *testing.T) {
-
-		prsr := parser.NewWebPageParser()
-
-		count, err := prsr.GetExternalLinkCount()
-
-		if err == nil {
-			t.Fatalf("Expected error but got none")
+	// The page moves from localhost to 127.0.0.1, so that its links are only
+	// internal when classified against the URL it ends up at.
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/page", http.StatusFound)
+			return
 		}
-
-		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
-			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
-		}
-
-		if count != 0 {
-			t.Fatalf("Expected count of 0, got %v", count)
+		fmt.Fprint(w, `<html><body><a href="/about">About</a><a href="http://127.0.0.1/contact">Contact</a><a href="http://localhost/">Old</a></body></html>`)
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/old", http.StatusMovedPermanently)
+	}))
+	defer origin.Close()
+	location := strings.Replace(origin.URL, "127.0.0.1", "localhost", 1) + "/start"
+
+	t.Run("should record the redirects and use the final URL", func(t *testing.T) {
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+
+		doc, err := prsr.DownloadDocument(context.Background(), location, model.FetchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
 		}
-	})
 
-	t.Run("should return correct external link count", func(t *testing.T) {
-
-		tests := []struct {
-			name          string
-			html          string
-			expectedCount int
-			pageURL       string
-		}{
-			{
-				name:          "one external link",
-				html:          `<html><body><a href="https://www.home24.com"></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "two external links",
-				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="https://www.google.com"></a></div></body></html>`,
-				expectedCount: 2,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "ignore internal links",
-				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="/home"></a><a hrel="http://localhost/contact"></a></div></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "no external links",
-				html:          `<html><body><div><a href="/home"></a></div></body></html>`,
-				expectedCount: 0,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "ignore internal links that contain hostname",
-				html:          `<html><body><div><a href="http://localhost/home"></a></div></body></html>`,
-				expectedCount: 0,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "sub domains count as externals",
-				html:          `<html><body><div><a href="http://support.home24.de"></a></div></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://home24.de",
-			},
+		expected := []model.Redirect{
+			{URL: location, StatusCode: http.StatusMovedPermanently, Location: target.URL + "/old"},
+			{URL: target.URL + "/old", StatusCode: http.StatusFound, Location: "/page"},
 		}
-
-		for _, tcase := range tests {
-			t.Run(tcase.name, func(t *testing.T) {
-
-				prsr := parser.NewWebPageParser()
-				prsr.FromString(tcase.html, tcase.pageURL)
-				count, err := prsr.GetExternalLinkCount()
-				if err != nil {
-					t.Fatalf("Unexpecter error: %v", err)
-				}
-				if count != tcase.expectedCount {
-					t.Fatalf("Expected count %v, got %v", tcase.expectedCount, count)
-				}
-			})
+		if fmt.Sprint(doc.Redirects()) != fmt.Sprint(expected) {
+			t.Errorf("Expected redirects %v, got %v", expected, doc.Redirects())
 		}
-	})
-}
-
-func TestGetInternalLinkCount(t *testing.T) {
-	t.Parallel()
-	t.Run("should return error when document is not loaded", func(t *testing.T) {
-
-		prsr := parser.NewWebPageParser()
-
-		count, err := prsr.GetInternalLinkCount()
-
-		if err == nil {
-			t.Fatalf("Expected error but got none")
+		if doc.URL() != target.URL+"/page" {
+			t.Errorf("Expected URL %v, got %v", target.URL+"/page", doc.URL())
 		}
-
-		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
-			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		if count, _ := analyze[parser.InternalLinkCount](parser.NewInternalLinkCountAnalyzer(), doc); count != 2 {
+			t.Errorf("Expected 2 internal links, got %v", count)
 		}
-
-		if count != 0 {
-			t.Fatalf("Expected count of 0, got %v", count)
+		if count, _ := analyze[parser.ExternalLinkCount](parser.NewExternalLinkCountAnalyzer(), doc); count != 1 {
+			t.Errorf("Expected 1 external link, got %v", count)
 		}
 	})
 
-	t.Run("should return correct internal link count", func(t *testing.T) {
-
-		tests := []struct {
-			name          string
-			html          string
-			expectedCount int
-			pageURL       string
-		}{
-			{
-				name:          "one internal relative link",
-				html:          `<html><body><a href="/home"></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "one internal full path link",
-				html:          `<html><body><a href="http://localhost/home"></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "two internal links",
-				html:          `<html><body><div><a href="http://localhost/home"></a><a href="/contact"></a></div></body></html>`,
-				expectedCount: 2,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "ignore external links",
-				html:          `<html><body><div><a href="https://www.home24.com"></a><a href="/home"></a><a hrel="http://localhost/contact"></a></div></body></html>`,
-				expectedCount: 1,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "no links",
-				html:          `<html><body><div></div></body></html>`,
-				expectedCount: 0,
-				pageURL:       "http://localhost",
-			},
-			{
-				name:          "sub domains don't count as internal",
-				html:          `<html><body><div><a href="http://support.home24.de"></a></div></body></html>`,
-				expectedCount: 0,
-				pageURL:       "http://home24.de",
-			},
+	t.Run("should not send credentials to the host it was redirected to", func(t *testing.T) {
+		authorizations := make(chan string, 1)
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/about" {
+				authorizations <- r.Header.Get("Authorization")
+				return
+			}
+			fmt.Fprint(w, `<html><body><a href="/about">About</a></body></html>`)
+		}))
+		defer other.Close()
+		redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, other.URL+"/page", http.StatusFound)
+		}))
+		defer redirecting.Close()
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
+
+		doc, err := prsr.DownloadDocument(context.Background(), redirecting.URL, model.FetchOptions{BearerToken: "secret"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := newInaccessibleLinksAnalyzer().Analyze(context.Background(), doc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		for _, tcase := range tests {
-			t.Run(tcase.name, func(t *testing.T) {
-
-				prsr := parser.NewWebPageParser()
-				prsr.FromString(tcase.html, tcase.pageURL)
-				count, err := prsr.GetInternalLinkCount()
-				if err != nil {
-					t.Fatalf("Unexpecter error: %v", err)
-				}
-				if count != tcase.expectedCount {
-					t.Fatalf("Expected count %v, got %v", tcase.expectedCount, count)
-				}
-			})
+		if authorization := <-authorizations; authorization != "" {
+			t.Fatalf("Expected no Authorization header on the other host, got %q", authorization)
 		}
 	})
-}
-
-func TestGetContainsLogin(t *testing.T) {
-	t.Parallel()
-	t.Run("should return error when document is not loaded", func(t *testing.T) {
-
-		prsr := parser.NewWebPageParser()
 
-		_, err := prsr.GetContainsLogin()
+	t.Run("should return error when there are too many redirects", func(t *testing.T) {
+		maxRedirects := 1
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{MaxRedirects: &maxRedirects}), parser.Config{})
 
-		if err == nil {
-			t.Fatalf("Expected error but got none")
-		}
+		_, err := prsr.DownloadDocument(context.Background(), location, model.FetchOptions{})
 
-		if !errors.Is(err, parser.ErrDocumentNotLoaded) {
-			t.Fatalf("Expected ErrDocumentNotLoaded, got %v", err)
+		if !errors.Is(err, ports.ErrTooManyRedirects) || errors.Is(err, ports.ErrUnreachableHost) {
+			t.Fatalf("Expected ErrTooManyRedirects, got %v", err)
 		}
-
 	})
 
-	t.Run("should return true when html has a login form", func(t *testing.T) {
-		tests := []struct {
-			name            string
-			html            string
-			expectedOutcome bool
-		}{
-			{
-				name:            "contains a login form with user and password",
-				html:            `<html><body><form><input type="text"></input><input type="password"></input></form></body></html>`,
-				expectedOutcome: true,
-			},
-			{
-				name:            "contains a login form with email and password",
-				html:            `<html><body><form><input type="email"></input><input type="password"></input></form></body></html>`,
-				expectedOutcome: true,
-			},
-			{
-				name:            "contains form with email and no password",
-				html:            `<html><body><form><input type="email"></input></form></body></html>`,
-				expectedOutcome: false,
-			},
-			{
-				name:            "contains a form with only password",
-				html:            `<html><body><form><input type="password"></input></form></body></html>`,
-				expectedOutcome: false,
-			},
-			{
-				name:            "contains an empty form",
-				html:            `<html><body><form></form></body></html>`,
-				expectedOutcome: false,
-			},
-			{
-				name:            "contains no form",
-				html:            `<html><body></body></html>`,
-				expectedOutcome: false,
-			},
-		}
-
-		for _, tcase := range tests {
-			t.Run(tcase.name, func(t *testing.T) {
-				prsr := parser.NewWebPageParser()
-				err := prsr.FromString(tcase.html, "http://localhost")
-				if err != nil {
-					t.Fatalf("Unexpected error: could not load document")
-				}
+	t.Run("should not record redirects when there are none", func(t *testing.T) {
+		prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
 
-				result, err := prsr.GetContainsLogin()
-				if err != nil {
-					t.Fatalf("Unexpected error: %v", err)
-				}
+		doc, err := prsr.DownloadDocument(context.Background(), target.URL+"/page", model.FetchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
 
-				if result != tcase.expectedOutcome {
-					t.Fatalf("Expected outcome: %v, actual: %v", tcase.expectedOutcome, result)
-				}
-			})
+		if len(doc.Redirects()) != 0 {
+			t.Fatalf("Expected no redirects, got %v", doc.Redirects())
 		}
 	})
-
 }
 
-func TestHeaderCounts(t *testing.T) {
+func TestDownloadDocument_ResponseInfo(t *testing.T) {
 	t.Parallel()
+	page := "<html><head><title>Home</title></head></html>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/gzip" && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			fmt.Fprint(gz, page)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(page)))
+		fmt.Fprint(w, page)
+	}))
+	defer srv.Close()
+
 	tests := []struct {
-		name    string
-		html    string
-		h1Count int
-		h2Count int
-		h3Count int
-		h4Count int
-		h5Count int
-		h6Count int
+		name                  string
+		path                  string
+		expectedCompression   string
+		expectedContentLength int64
 	}{
 		{
-			name:    "no headers",
-			html:    "<html><body><p>No headers here</p></body></html>",
-			h1Count: 0, h2Count: 0, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
+			name:                  "uncompressed",
+			path:                  "/",
+			expectedContentLength: int64(len(page)),
 		},
 		{
-			name:    "single header of each type",
-			html:    "<html><body><h1>H1</h1><h2>H2</h2><h3>H3</h3><h4>H4</h4><h5>H5</h5><h6>H6</h6></body></html>",
-			h1Count: 1, h2Count: 1, h3Count: 1, h4Count: 1, h5Count: 1, h6Count: 1,
-		},
-		{
-			name:    "multiple headers of same type",
-			html:    "<html><body><h1>First</h1><h1>Second</h1><h2>H2</h2></body></html>",
-			h1Count: 2, h2Count: 1, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
-		},
-		{
-			name:    "nested headers",
-			html:    "<html><body><div><h1>Title</h1><section><h2>Section</h2></section></div></body></html>",
-			h1Count: 1, h2Count: 1, h3Count: 0, h4Count: 0, h5Count: 0, h6Count: 0,
+			name:                  "gzip",
+			path:                  "/gzip",
+			expectedCompression:   "gzip",
+			expectedContentLength: -1,
 		},
 	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			prsr := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{})
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			parser := parser.NewWebPageParser()
-			err := parser.FromString(tt.html, "")
+			doc, err := prsr.DownloadDocument(context.Background(), srv.URL+tcase.path, model.FetchOptions{})
 			if err != nil {
-				t.Fatalf("Failed to load document: %v", err)
+				t.Fatalf("Unexpected error: %v", err)
 			}
 
-			// Test H1 count
-			count, err := parser.GetHeaderOneCount()
-			if err != nil {
-				t.Errorf("GetHeaderOneCount() error: %v", err)
+			info := doc.ResponseInfo()
+			if info == nil {
+				t.Fatalf("Expected response info")
 			}
-			if count != tt.h1Count {
-				t.Errorf("Expected H1 count %d, got %d", tt.h1Count, count)
+			if info.StatusCode != http.StatusOK || info.Protocol != "HTTP/1.1" {
+				t.Errorf("Expected 200 over HTTP/1.1, got %v over %v", info.StatusCode, info.Protocol)
 			}
-
-			// Test H2 count
-			count, err = parser.GetHeaderTwoCount()
-			if err != nil {
-				t.Errorf("GetHeaderTwoCount() error: %v", err)
-			}
-			if count != tt.h2Count {
-				t.Errorf("Expected H2 count %d, got %d", tt.h2Count, count)
+			if info.Compression != tcase.expectedCompression {
+				t.Errorf("Expected compression %q, got %q", tcase.expectedCompression, info.Compression)
 			}
-
-			// Test H3 count
-			count, err = parser.GetHeaderThreeCount()
-			if err != nil {
-				t.Errorf("GetHeaderThreeCount() error: %v", err)
+			if info.ContentLength != tcase.expectedContentLength {
+				t.Errorf("Expected content length %v, got %v", tcase.expectedContentLength, info.ContentLength)
 			}
-			if count != tt.h3Count {
-				t.Errorf("Expected H3 count %d, got %d", tt.h3Count, count)
+			if info.BodySize != int64(len(page)) {
+				t.Errorf("Expected body size %v, got %v", len(page), info.BodySize)
 			}
-
-			// Test H4 count
-			count, err = parser.GetHeaderFourCount()
-			if err != nil {
-				t.Errorf("GetHeaderFourCount() error: %v", err)
-			}
-			if count != tt.h4Count {
-				t.Errorf("Expected H4 count %d, got %d", tt.h4Count, count)
-			}
-
-			// Test H5 count
-			count, err = parser.GetHeaderFiveCount()
-			if err != nil {
-				t.Errorf("GetHeaderFiveCount() error: %v", err)
-			}
-			if count != tt.h5Count {
-				t.Errorf("Expected H5 count %d, got %d", tt.h5Count, count)
-			}
-
-			// Test H6 count
-			count, err = parser.GetHeaderSixCount()
-			if err != nil {
-				t.Errorf("GetHeaderSixCount() error: %v", err)
+			if info.Server != "nginx" || info.CacheControl != "max-age=60" || info.ETag != `"abc"` ||
+				info.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+				t.Errorf("Expected the caching headers, got %+v", info)
 			}
-			if count != tt.h6Count {
-				t.Errorf("Expected H6 count %d, got %d", tt.h6Count, count)
+			if info.TimeToFirstByte <= 0 || info.TotalTime < info.TimeToFirstByte {
+				t.Errorf("Expected the total time to include the time to first byte, got %v and %v", info.TotalTime, info.TimeToFirstByte)
 			}
 		})
 	}
-}
-
-func TestHeaderCounts_DocumentNotLoaded(t *testing.T) {
-	t.Parallel()
-	prsr := parser.NewWebPageParser()
 
-	tests := []struct {
-		name     string
-		testFunc func() (int, error)
-	}{
-		{"GetHeaderOneCount", prsr.GetHeaderOneCount},
-		{"GetHeaderTwoCount", prsr.GetHeaderTwoCount},
-		{"GetHeaderThreeCount", prsr.GetHeaderThreeCount},
-		{"GetHeaderFourCount", prsr.GetHeaderFourCount},
-		{"GetHeaderFiveCount", prsr.GetHeaderFiveCount},
-		{"GetHeaderSixCount", prsr.GetHeaderSixCount},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			count, err := tt.testFunc()
-
-			if err == nil {
-				t.Error("Expected error but got none")
-			}
-
-			if !errors.Is(err, parser.ErrDocumentNotLoaded) {
-				t.Errorf("Expected ErrDocumentNotLoaded, got %v", err)
-			}
+	t.Run("should not have response info for documents that were not downloaded", func(t *testing.T) {
+		doc, err := parser.NewWebPageParser(fetcher.NewHTTPFetcher(fetcher.Config{}), parser.Config{}).FromString(page, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
 
-			if count != 0 {
-				t.Errorf("Expected count 0, got %d", count)
-			}
-		})
-	}
+		if doc.ResponseInfo() != nil {
+			t.Fatalf("Expected no response info, got %+v", doc.ResponseInfo())
+		}
+	})
 }
@@ -0,0 +1,193 @@
+package presenter
+
+import (
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// WebPageReportBody is the JSON representation of a report.
+type WebPageReportBody struct {
+	ID        string         `json:"id,omitempty"`
+	URL       string         `json:"url"`
+	CreatedAt string         `json:"createdAt,omitempty"`
+	FinalURL  string         `json:"finalUrl"`
+	Redirects []RedirectBody `json:"redirects"`
+	Charset   string         `json:"charset"`
+
+	ResponseInfo *ResponseInfoBody `json:"responseInfo,omitempty"`
+	TLS          *TLSBody          `json:"tls,omitempty"`
+
+	DocumentVersion   string `json:"documentVersion"`
+	Title             string `json:"title"`
+	ExternalLinkCount int    `json:"externalLinkCount"`
+	InternalLinkCount int    `json:"internalLinkCount"`
+	ContainsLogin     bool   `json:"containsLogin"`
+	HeaderOneCount    int    `json:"headerOneCount"`
+	HeaderTwoCount    int    `json:"headerTwoCount"`
+	HeaderThreeCount  int    `json:"headerThreeCount"`
+	HeaderFourCount   int    `json:"headerFourCount"`
+	HeaderFiveCount   int    `json:"headerFiveCount"`
+	HeaderSixCount    int    `json:"headerSixCount"`
+
+	InaccessibleLinkCount int                    `json:"inaccessibleLinkCount"`
+	InaccessibleLinks     []InaccessibleLinkBody `json:"inaccessibleLinks"`
+
+	Analyses map[string]any     `json:"analyses,omitempty"`
+	Warnings []FieldWarningBody `json:"warnings,omitempty"`
+}
+
+type RedirectBody struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Location   string `json:"location"`
+}
+
+// ResponseInfoBody describes the response the page was served with. Times are
+// in milliseconds.
+type ResponseInfoBody struct {
+	StatusCode      int    `json:"statusCode"`
+	Protocol        string `json:"protocol"`
+	TimeToFirstByte int64  `json:"timeToFirstByteMs"`
+	TotalTime       int64  `json:"totalTimeMs"`
+	ContentLength   int64  `json:"contentLength"`
+	BodySize        int64  `json:"bodySize"`
+	Compression     string `json:"compression,omitempty"`
+	Server          string `json:"server,omitempty"`
+	CacheControl    string `json:"cacheControl,omitempty"`
+	LastModified    string `json:"lastModified,omitempty"`
+	ETag            string `json:"etag,omitempty"`
+}
+
+func newResponseInfoBody(info *model.ResponseInfo) *ResponseInfoBody {
+	if info == nil {
+		return nil
+	}
+	return &ResponseInfoBody{
+		StatusCode:      info.StatusCode,
+		Protocol:        info.Protocol,
+		TimeToFirstByte: info.TimeToFirstByte.Milliseconds(),
+		TotalTime:       info.TotalTime.Milliseconds(),
+		ContentLength:   info.ContentLength,
+		BodySize:        info.BodySize,
+		Compression:     info.Compression,
+		Server:          info.Server,
+		CacheControl:    info.CacheControl,
+		LastModified:    info.LastModified,
+		ETag:            info.ETag,
+	}
+}
+
+type TLSBody struct {
+	Version      string            `json:"version"`
+	CipherSuite  string            `json:"cipherSuite"`
+	Certificates []CertificateBody `json:"certificates"`
+	Warnings     []string          `json:"warnings,omitempty"`
+}
+
+type CertificateBody struct {
+	Subject       string   `json:"subject"`
+	Issuer        string   `json:"issuer"`
+	SANs          []string `json:"sans"`
+	NotBefore     string   `json:"notBefore"`
+	NotAfter      string   `json:"notAfter"`
+	DaysRemaining int      `json:"daysRemaining"`
+}
+
+func newTLSBody(info *model.TLSInfo) *TLSBody {
+	if info == nil {
+		return nil
+	}
+	body := &TLSBody{
+		Version:      info.Version,
+		CipherSuite:  info.CipherSuite,
+		Certificates: []CertificateBody{},
+		Warnings:     info.Warnings,
+	}
+	for _, cert := range info.Certificates {
+		body.Certificates = append(body.Certificates, CertificateBody{
+			Subject:       cert.Subject,
+			Issuer:        cert.Issuer,
+			SANs:          cert.SANs,
+			NotBefore:     FormatTime(cert.NotBefore),
+			NotAfter:      FormatTime(cert.NotAfter),
+			DaysRemaining: cert.DaysRemaining,
+		})
+	}
+	return body
+}
+
+type InaccessibleLinkBody struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type FieldWarningBody struct {
+	Field   string `json:"field"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewWebPageReportBody maps a report to the JSON representation shared by the
+// API and the command line tool.
+func NewWebPageReportBody(report model.WebPageReport) *WebPageReportBody {
+	resBody := &WebPageReportBody{
+		ID:        report.ID,
+		URL:       report.URL,
+		CreatedAt: FormatTime(report.CreatedAt),
+		FinalURL:  report.FinalURL,
+		Redirects: []RedirectBody{},
+		Charset:   report.Charset,
+
+		ResponseInfo: newResponseInfoBody(report.ResponseInfo),
+		TLS:          newTLSBody(report.TLS),
+
+		DocumentVersion:   report.DocumentVersion,
+		Title:             report.Title,
+		ExternalLinkCount: report.ExternalLinkCount,
+		InternalLinkCount: report.InternalLinkCount,
+		ContainsLogin:     report.ContainsLogin,
+		HeaderOneCount:    report.HeaderOneCount,
+		HeaderTwoCount:    report.HeaderTwoCount,
+		HeaderThreeCount:  report.HeaderThreeCount,
+		HeaderFourCount:   report.HeaderFourCount,
+		HeaderFiveCount:   report.HeaderFiveCount,
+		HeaderSixCount:    report.HeaderSixCount,
+
+		InaccessibleLinkCount: report.InaccessibleLinkCount,
+		InaccessibleLinks:     []InaccessibleLinkBody{},
+
+		Analyses: report.Analyses,
+	}
+	for _, redirect := range report.Redirects {
+		resBody.Redirects = append(resBody.Redirects, RedirectBody{
+			URL:        redirect.URL,
+			StatusCode: redirect.StatusCode,
+			Location:   redirect.Location,
+		})
+	}
+	for _, link := range report.InaccessibleLinks {
+		resBody.InaccessibleLinks = append(resBody.InaccessibleLinks, InaccessibleLinkBody{
+			URL:        link.URL,
+			StatusCode: link.StatusCode,
+			Error:      link.Error,
+		})
+	}
+	for _, warning := range report.Warnings {
+		resBody.Warnings = append(resBody.Warnings, FieldWarningBody{
+			Field:   warning.Field,
+			Status:  string(warning.Status),
+			Message: warning.Message,
+		})
+	}
+	return resBody
+}
+
+// FormatTime formats t as RFC 3339, or returns an empty string when t is zero.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// FileReportRepository stores reports in a single append-only file, one JSON
+// document per line, and keeps an in-memory index of them for reads. Saving a
+// report with an existing ID appends a new version that replaces the old one
+// when the file is loaded again. The file is never compacted, and every report
+// it holds is kept in memory, so it suits stores of a few thousand reports.
+type FileReportRepository struct {
+	*MemoryReportRepository
+	file *os.File
+}
+
+// OpenFileReportRepository loads the reports stored at path, creating the file
+// if it does not exist.
+func OpenFileReportRepository(path string) (*FileReportRepository, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open report store: %w", err)
+	}
+	r := &FileReportRepository{
+		// Every report of the file is indexed, as the file keeps them all anyway.
+		MemoryReportRepository: NewMemoryReportRepository(MemoryConfig{MaxReports: -1}),
+		file:                   file,
+	}
+	if err := r.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *FileReportRepository) load() error {
+	scanner := bufio.NewScanner(r.file)
+	scanner.Buffer(nil, 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var record reportRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("report store is corrupted at line %d: %w", line, err)
+		}
+		r.add(record.report())
+	}
+	return scanner.Err()
+}
+
+// Save implements the ReportRepository interface. The report is written to disk
+// before being visible to readers.
+func (r *FileReportRepository) Save(ctx context.Context, report model.WebPageReport) error {
+	b, err := json.Marshal(newReportRecord(report))
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	r.add(report)
+	return nil
+}
+
+func (r *FileReportRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.file.Sync(), r.file.Close())
+}
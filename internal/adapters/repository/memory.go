@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const defaultMaxReports = 1000
+
+type MemoryConfig struct {
+	// MaxReports is the number of reports kept, the oldest ones being evicted
+	// first. Zero keeps 1000 of them and a negative number keeps them all.
+	MaxReports int
+}
+
+// MemoryReportRepository keeps reports in memory. They are lost when the
+// process exits, which makes it meant for development rather than production.
+type MemoryReportRepository struct {
+	mu   sync.RWMutex
+	byID map[string]model.WebPageReport
+	// ids holds the IDs of the reports in the order they were first saved.
+	ids []string
+	cfg MemoryConfig
+}
+
+func NewMemoryReportRepository(cfg MemoryConfig) *MemoryReportRepository {
+	if cfg.MaxReports == 0 {
+		cfg.MaxReports = defaultMaxReports
+	}
+	return &MemoryReportRepository{
+		byID: map[string]model.WebPageReport{},
+		cfg:  cfg,
+	}
+}
+
+// Save implements the ReportRepository interface.
+func (r *MemoryReportRepository) Save(ctx context.Context, report model.WebPageReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(report)
+	return nil
+}
+
+// add stores report, replacing the one with the same ID, and evicts the oldest
+// report when there are more than Config.MaxReports.
+func (r *MemoryReportRepository) add(report model.WebPageReport) {
+	if _, ok := r.byID[report.ID]; !ok {
+		r.ids = append(r.ids, report.ID)
+	}
+	r.byID[report.ID] = report
+	if r.cfg.MaxReports > 0 && len(r.ids) > r.cfg.MaxReports {
+		delete(r.byID, r.ids[0])
+		r.ids = slices.Delete(r.ids, 0, 1)
+	}
+}
+
+// FindByID implements the ReportRepository interface.
+func (r *MemoryReportRepository) FindByID(ctx context.Context, id string) (model.WebPageReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	report, ok := r.byID[id]
+	if !ok {
+		return model.WebPageReport{}, fmt.Errorf("%w: %v", ports.ErrReportNotFound, id)
+	}
+	return report, nil
+}
+
+// FindByURL implements the ReportRepository interface.
+func (r *MemoryReportRepository) FindByURL(ctx context.Context, url string) ([]model.WebPageReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reports := []model.WebPageReport{}
+	for _, id := range slices.Backward(r.ids) {
+		if report := r.byID[id]; url == "" || report.URL == url {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// reportRecord is the representation of a report in the report store, so that
+// the domain model can change without making stored reports unreadable. Its
+// keys are the names of the fields of the model in camel case, which files
+// written before it existed are read with as well, as keys are matched without
+// regard to case.
+type reportRecord struct {
+	ID        string           `json:"id"`
+	URL       string           `json:"url"`
+	CreatedAt time.Time        `json:"createdAt"`
+	FinalURL  string           `json:"finalUrl"`
+	Redirects []redirectRecord `json:"redirects"`
+
+	ResponseInfo *responseInfoRecord `json:"responseInfo"`
+	TLS          *tlsRecord          `json:"tls"`
+	Charset      string              `json:"charset"`
+
+	DocumentVersion   string `json:"documentVersion"`
+	Title             string `json:"title"`
+	ExternalLinkCount int    `json:"externalLinkCount"`
+	InternalLinkCount int    `json:"internalLinkCount"`
+	ContainsLogin     bool   `json:"containsLogin"`
+	HeaderOneCount    int    `json:"headerOneCount"`
+	HeaderTwoCount    int    `json:"headerTwoCount"`
+	HeaderThreeCount  int    `json:"headerThreeCount"`
+	HeaderFourCount   int    `json:"headerFourCount"`
+	HeaderFiveCount   int    `json:"headerFiveCount"`
+	HeaderSixCount    int    `json:"headerSixCount"`
+
+	InaccessibleLinkCount int                      `json:"inaccessibleLinkCount"`
+	InaccessibleLinks     []inaccessibleLinkRecord `json:"inaccessibleLinks"`
+
+	// Analyses are stored as their JSON representation, and read back as
+	// generic maps and slices.
+	Analyses map[string]any       `json:"analyses"`
+	Warnings []fieldWarningRecord `json:"warnings"`
+}
+
+type redirectRecord struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Location   string `json:"location"`
+}
+
+// responseInfoRecord stores durations in nanoseconds.
+type responseInfoRecord struct {
+	StatusCode      int           `json:"statusCode"`
+	Protocol        string        `json:"protocol"`
+	TimeToFirstByte time.Duration `json:"timeToFirstByte"`
+	TotalTime       time.Duration `json:"totalTime"`
+	ContentLength   int64         `json:"contentLength"`
+	BodySize        int64         `json:"bodySize"`
+	Compression     string        `json:"compression"`
+	Server          string        `json:"server"`
+	CacheControl    string        `json:"cacheControl"`
+	LastModified    string        `json:"lastModified"`
+	ETag            string        `json:"etag"`
+}
+
+type tlsRecord struct {
+	Version      string              `json:"version"`
+	CipherSuite  string              `json:"cipherSuite"`
+	Certificates []certificateRecord `json:"certificates"`
+	Warnings     []string            `json:"warnings"`
+}
+
+type certificateRecord struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans"`
+	NotBefore     time.Time `json:"notBefore"`
+	NotAfter      time.Time `json:"notAfter"`
+	DaysRemaining int       `json:"daysRemaining"`
+}
+
+type inaccessibleLinkRecord struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+}
+
+type fieldWarningRecord struct {
+	Field   string `json:"field"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func newReportRecord(report model.WebPageReport) reportRecord {
+	return reportRecord{
+		ID:        report.ID,
+		URL:       report.URL,
+		CreatedAt: report.CreatedAt,
+		FinalURL:  report.FinalURL,
+		Redirects: mapSlice(report.Redirects, func(r model.Redirect) redirectRecord {
+			return redirectRecord(r)
+		}),
+
+		ResponseInfo: mapPointer(report.ResponseInfo, func(info model.ResponseInfo) responseInfoRecord {
+			return responseInfoRecord(info)
+		}),
+		TLS: mapPointer(report.TLS, func(info model.TLSInfo) tlsRecord {
+			return tlsRecord{
+				Version:     info.Version,
+				CipherSuite: info.CipherSuite,
+				Certificates: mapSlice(info.Certificates, func(c model.Certificate) certificateRecord {
+					return certificateRecord(c)
+				}),
+				Warnings: info.Warnings,
+			}
+		}),
+		Charset: report.Charset,
+
+		DocumentVersion:   report.DocumentVersion,
+		Title:             report.Title,
+		ExternalLinkCount: report.ExternalLinkCount,
+		InternalLinkCount: report.InternalLinkCount,
+		ContainsLogin:     report.ContainsLogin,
+		HeaderOneCount:    report.HeaderOneCount,
+		HeaderTwoCount:    report.HeaderTwoCount,
+		HeaderThreeCount:  report.HeaderThreeCount,
+		HeaderFourCount:   report.HeaderFourCount,
+		HeaderFiveCount:   report.HeaderFiveCount,
+		HeaderSixCount:    report.HeaderSixCount,
+
+		InaccessibleLinkCount: report.InaccessibleLinkCount,
+		InaccessibleLinks: mapSlice(report.InaccessibleLinks, func(l model.InaccessibleLink) inaccessibleLinkRecord {
+			return inaccessibleLinkRecord(l)
+		}),
+
+		Analyses: report.Analyses,
+		Warnings: mapSlice(report.Warnings, func(w model.FieldWarning) fieldWarningRecord {
+			return fieldWarningRecord{Field: w.Field, Status: string(w.Status), Message: w.Message}
+		}),
+	}
+}
+
+func (r reportRecord) report() model.WebPageReport {
+	return model.WebPageReport{
+		ID:        r.ID,
+		URL:       r.URL,
+		CreatedAt: r.CreatedAt,
+		FinalURL:  r.FinalURL,
+		Redirects: mapSlice(r.Redirects, func(r redirectRecord) model.Redirect {
+			return model.Redirect(r)
+		}),
+
+		ResponseInfo: mapPointer(r.ResponseInfo, func(info responseInfoRecord) model.ResponseInfo {
+			return model.ResponseInfo(info)
+		}),
+		TLS: mapPointer(r.TLS, func(info tlsRecord) model.TLSInfo {
+			return model.TLSInfo{
+				Version:     info.Version,
+				CipherSuite: info.CipherSuite,
+				Certificates: mapSlice(info.Certificates, func(c certificateRecord) model.Certificate {
+					return model.Certificate(c)
+				}),
+				Warnings: info.Warnings,
+			}
+		}),
+		Charset: r.Charset,
+
+		DocumentVersion:   r.DocumentVersion,
+		Title:             r.Title,
+		ExternalLinkCount: r.ExternalLinkCount,
+		InternalLinkCount: r.InternalLinkCount,
+		ContainsLogin:     r.ContainsLogin,
+		HeaderOneCount:    r.HeaderOneCount,
+		HeaderTwoCount:    r.HeaderTwoCount,
+		HeaderThreeCount:  r.HeaderThreeCount,
+		HeaderFourCount:   r.HeaderFourCount,
+		HeaderFiveCount:   r.HeaderFiveCount,
+		HeaderSixCount:    r.HeaderSixCount,
+
+		InaccessibleLinkCount: r.InaccessibleLinkCount,
+		InaccessibleLinks: mapSlice(r.InaccessibleLinks, func(l inaccessibleLinkRecord) model.InaccessibleLink {
+			return model.InaccessibleLink(l)
+		}),
+
+		Analyses: r.Analyses,
+		Warnings: mapSlice(r.Warnings, func(w fieldWarningRecord) model.FieldWarning {
+			return model.FieldWarning{Field: w.Field, Status: model.FieldStatus(w.Status), Message: w.Message}
+		}),
+	}
+}
+
+// mapSlice converts every element of s with f, keeping nil slices nil.
+func mapSlice[S any, T any](s []S, f func(S) T) []T {
+	if s == nil {
+		return nil
+	}
+	mapped := make([]T, 0, len(s))
+	for _, v := range s {
+		mapped = append(mapped, f(v))
+	}
+	return mapped
+}
+
+// mapPointer converts the value p points to with f, keeping nil pointers nil.
+func mapPointer[S any, T any](p *S, f func(S) T) *T {
+	if p == nil {
+		return nil
+	}
+	mapped := f(*p)
+	return &mapped
+}
@@ -0,0 +1,273 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/repository"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+func TestReportRepositories(t *testing.T) {
+	t.Parallel()
+	repositories := []struct {
+		name string
+		open func(t *testing.T) ports.ReportRepository
+	}{
+		{"memory", func(t *testing.T) ports.ReportRepository {
+			return repository.NewMemoryReportRepository(repository.MemoryConfig{})
+		}},
+		{"file", func(t *testing.T) ports.ReportRepository {
+			r, err := repository.OpenFileReportRepository(filepath.Join(t.TempDir(), "reports.jsonl"))
+			if err != nil {
+				t.Fatalf("Failed to open repository: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+			return r
+		}},
+	}
+
+	for _, repo := range repositories {
+		t.Run(repo.name, func(t *testing.T) {
+			t.Run("should find saved reports by ID", func(t *testing.T) {
+				r := repo.open(t)
+				report := model.WebPageReport{ID: "1", URL: "http://localhost", Title: "Test"}
+				if err := r.Save(context.Background(), report); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				found, err := r.FindByID(context.Background(), "1")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if found.Title != "Test" {
+					t.Fatalf("Expected title %q, got %q", "Test", found.Title)
+				}
+			})
+
+			t.Run("should return not found error for unknown IDs", func(t *testing.T) {
+				r := repo.open(t)
+
+				_, err := r.FindByID(context.Background(), "unknown")
+
+				if !errors.Is(err, ports.ErrReportNotFound) {
+					t.Fatalf("Expected ErrReportNotFound, got %v", err)
+				}
+			})
+
+			t.Run("should list reports by URL newest first", func(t *testing.T) {
+				r := repo.open(t)
+				for _, report := range []model.WebPageReport{
+					{ID: "1", URL: "http://localhost"},
+					{ID: "2", URL: "http://home24.de"},
+					{ID: "3", URL: "http://localhost"},
+				} {
+					if err := r.Save(context.Background(), report); err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+				}
+
+				byURL, err := r.FindByURL(context.Background(), "http://localhost")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(byURL) != 2 || byURL[0].ID != "3" || byURL[1].ID != "1" {
+					t.Fatalf("Expected reports 3 and 1, got %+v", byURL)
+				}
+
+				all, err := r.FindByURL(context.Background(), "")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(all) != 3 {
+					t.Fatalf("Expected 3 reports, got %v", len(all))
+				}
+			})
+		})
+	}
+}
+
+func TestFileReportRepository_Reopen(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	r, err := repository.OpenFileReportRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	reports := []model.WebPageReport{
+		{ID: "1", URL: "http://localhost", Title: "First", CreatedAt: createdAt},
+		{ID: "1", URL: "http://localhost", Title: "Updated", CreatedAt: createdAt},
+		{
+			ID:                "2",
+			URL:               "http://localhost",
+			InaccessibleLinks: []model.InaccessibleLink{{URL: "http://localhost/missing", StatusCode: 404}},
+			Analyses:          map[string]any{"seo": map[string]any{"score": 80}},
+		},
+	}
+	for _, report := range reports {
+		if err := r.Save(context.Background(), report); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := repository.OpenFileReportRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	defer reopened.Close()
+
+	first, err := reopened.FindByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Title != "Updated" || !first.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected the latest version of report 1, got %+v", first)
+	}
+	second, err := reopened.FindByID(context.Background(), "2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(second.InaccessibleLinks) != 1 || second.InaccessibleLinks[0].StatusCode != 404 {
+		t.Errorf("Expected inaccessible links to be stored, got %+v", second.InaccessibleLinks)
+	}
+	if _, ok := second.Analyses["seo"]; !ok {
+		t.Errorf("Expected analyses to be stored, got %+v", second.Analyses)
+	}
+	all, _ := reopened.FindByURL(context.Background(), "")
+	if len(all) != 2 {
+		t.Errorf("Expected 2 reports, got %v", len(all))
+	}
+}
+
+func TestFileReportRepository_RoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+	notAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Analyses are read back as generic values, so they are given as such.
+	report := model.WebPageReport{
+		ID:        "1",
+		URL:       "http://localhost",
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 500, time.UTC),
+		FinalURL:  "https://localhost/home",
+		Redirects: []model.Redirect{{URL: "http://localhost", StatusCode: 301, Location: "https://localhost/home"}},
+		ResponseInfo: &model.ResponseInfo{
+			StatusCode: 200, Protocol: "HTTP/2.0", TimeToFirstByte: 120 * time.Millisecond, TotalTime: 250 * time.Millisecond,
+			ContentLength: -1, BodySize: 2048, Compression: "gzip", Server: "nginx", CacheControl: "no-cache",
+			LastModified: "Sun, 01 Jun 2025 12:00:00 GMT", ETag: `"abc"`,
+		},
+		TLS: &model.TLSInfo{
+			Version:     "TLS 1.3",
+			CipherSuite: "TLS_AES_128_GCM_SHA256",
+			Certificates: []model.Certificate{{
+				Subject: "CN=localhost", Issuer: "CN=Test CA", SANs: []string{"localhost"},
+				NotBefore: notAfter.AddDate(-1, 0, 0), NotAfter: notAfter, DaysRemaining: 30,
+			}},
+			Warnings: []string{"certificate CN=localhost expires in 30 days"},
+		},
+		Charset:               "utf-8",
+		DocumentVersion:       "5",
+		Title:                 "Home",
+		ExternalLinkCount:     3,
+		InternalLinkCount:     4,
+		ContainsLogin:         true,
+		HeaderOneCount:        1,
+		HeaderTwoCount:        2,
+		HeaderThreeCount:      3,
+		HeaderFourCount:       4,
+		HeaderFiveCount:       5,
+		HeaderSixCount:        6,
+		InaccessibleLinkCount: 2,
+		InaccessibleLinks: []model.InaccessibleLink{
+			{URL: "http://localhost/missing", StatusCode: 404},
+			{URL: "http://gone.localhost/", Error: "host could not be reached"},
+		},
+		Analyses: map[string]any{"seo": map[string]any{"score": float64(80), "issues": []any{"missing_description"}}},
+		Warnings: []model.FieldWarning{{Field: "documentVersion", Status: model.FieldStatusUnknown, Message: "no version"}},
+	}
+
+	r, err := repository.OpenFileReportRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	if err := r.Save(context.Background(), report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reopened, err := repository.OpenFileReportRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	defer reopened.Close()
+
+	found, err := reopened.FindByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, report) {
+		t.Fatalf("Expected the report to be read back as it was saved:\n%+v\ngot:\n%+v", report, found)
+	}
+}
+
+func TestFileReportRepository_LegacyFile(t *testing.T) {
+	t.Parallel()
+	// Reports used to be stored with the names of the fields of the model.
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+	legacy := `{"ID":"1","URL":"http://localhost","Title":"Old","HeaderOneCount":2,"InaccessibleLinks":[{"URL":"http://localhost/missing","StatusCode":404,"Error":""}]}` + "\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	r, err := repository.OpenFileReportRepository(path)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer r.Close()
+
+	found, err := r.FindByID(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if found.Title != "Old" || found.HeaderOneCount != 2 || len(found.InaccessibleLinks) != 1 || found.InaccessibleLinks[0].StatusCode != 404 {
+		t.Fatalf("Expected the legacy report to be read, got %+v", found)
+	}
+}
+
+func TestMemoryReportRepository_MaxReports(t *testing.T) {
+	t.Parallel()
+	r := repository.NewMemoryReportRepository(repository.MemoryConfig{MaxReports: 2})
+	for _, id := range []string{"1", "2", "1", "3"} {
+		if err := r.Save(context.Background(), model.WebPageReport{ID: id, URL: "http://localhost"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	t.Run("should evict the oldest report", func(t *testing.T) {
+		if _, err := r.FindByID(context.Background(), "1"); !errors.Is(err, ports.ErrReportNotFound) {
+			t.Fatalf("Expected ErrReportNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should keep the newest reports", func(t *testing.T) {
+		reports, err := r.FindByURL(context.Background(), "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(reports) != 2 || reports[0].ID != "3" || reports[1].ID != "2" {
+			t.Fatalf("Expected reports 3 and 2, got %+v", reports)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const defaultBatchConcurrency = 4
+const defaultBatchMaxSize = 50
+const defaultBatchTimeout = 2 * time.Minute
+
+type BatchConfig struct {
+	// Concurrency is the number of reports of a batch generated at the same time.
+	Concurrency int
+	// MaxSize is the maximum number of URLs of a single batch.
+	MaxSize int
+	// Timeout is the deadline for generating a whole batch. The reports that
+	// are not done by then fail with domain.ErrTimeout.
+	Timeout time.Duration
+}
+
+// BatchGenerator generates the reports of a batch of URLs with generator.
+type BatchGenerator struct {
+	generator ports.ReportGenerator
+	cfg       BatchConfig
+}
+
+func NewBatchGenerator(generator ports.ReportGenerator, cfg BatchConfig) *BatchGenerator {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = defaultBatchConcurrency
+	}
+	if cfg.MaxSize < 1 {
+		cfg.MaxSize = defaultBatchMaxSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultBatchTimeout
+	}
+	return &BatchGenerator{
+		generator: generator,
+		cfg:       cfg,
+	}
+}
+
+// GenerateWebPageReports implements the BatchService interface. Reports are
+// generated at most BatchConfig.Concurrency at a time. The options are shared
+// by every report, so they are validated once before any of them is generated.
+func (b *BatchGenerator) GenerateWebPageReports(ctx context.Context, locations []string, opts model.ReportOptions) ([]model.BatchResult, error) {
+	if len(locations) > b.cfg.MaxSize {
+		return nil, fmt.Errorf("%w: %d URLs, at most %d are allowed", ports.ErrBatchTooLarge, len(locations), b.cfg.MaxSize)
+	}
+	if err := b.generator.ValidateOptions(opts); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+	defer cancel()
+
+	sem := make(chan struct{}, b.cfg.Concurrency)
+	results := make([]model.BatchResult, len(locations))
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		results[i].URL = location
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					results[i].Err = fmt.Errorf("%w: %w", domain.ErrTimeout, ctx.Err())
+				}
+				return
+			}
+			results[i].Report, results[i].Err = b.generator.GenerateWebPageReport(ctx, location, opts)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,138 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/application"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// countingGenerator fails for the "fail" location and keeps track of the
+// maximum number of reports generated at the same time. Every report takes
+// delay, 10ms when it is zero, unless ctx is done first. Options with analyzers
+// are invalid.
+type countingGenerator struct {
+	delay   time.Duration
+	running atomic.Int32
+	max     atomic.Int32
+}
+
+func (g *countingGenerator) ValidateOptions(opts model.ReportOptions) error {
+	if len(opts.Analyzers) > 0 {
+		return domain.ErrUnknownAnalyzer
+	}
+	return nil
+}
+
+func (g *countingGenerator) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	running := g.running.Add(1)
+	defer g.running.Add(-1)
+	for {
+		max := g.max.Load()
+		if running <= max || g.max.CompareAndSwap(max, running) {
+			break
+		}
+	}
+	delay := g.delay
+	if delay == 0 {
+		delay = 10 * time.Millisecond
+	}
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return model.WebPageReport{}, fmt.Errorf("%w: %w", domain.ErrTimeout, ctx.Err())
+	}
+	if location == "fail" {
+		return model.WebPageReport{}, domain.ErrInvalidURL
+	}
+	return model.WebPageReport{URL: location}, nil
+}
+
+func TestGenerateWebPageReports(t *testing.T) {
+	t.Parallel()
+	t.Run("should return one result per URL in order", func(t *testing.T) {
+		generator := &countingGenerator{}
+		batches := application.NewBatchGenerator(generator, application.BatchConfig{Concurrency: 2})
+		locations := []string{"http://localhost/1", "fail", "http://localhost/2", "http://localhost/3", "http://localhost/4"}
+
+		results, err := batches.GenerateWebPageReports(context.Background(), locations, model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(results) != len(locations) {
+			t.Fatalf("Expected %d results, got %d", len(locations), len(results))
+		}
+		for i, result := range results {
+			if result.URL != locations[i] {
+				t.Errorf("Expected result %d to be for %v, got %v", i, locations[i], result.URL)
+			}
+		}
+		if !errors.Is(results[1].Err, domain.ErrInvalidURL) {
+			t.Errorf("Expected the failing URL to have an error, got %v", results[1].Err)
+		}
+		if results[0].Err != nil || results[0].Report.URL != locations[0] {
+			t.Errorf("Expected the other URLs to have a report, got %+v", results[0])
+		}
+		if max := generator.max.Load(); max > 2 {
+			t.Errorf("Expected at most 2 reports at the same time, got %d", max)
+		}
+	})
+
+	t.Run("should reject batches that are too large", func(t *testing.T) {
+		batches := application.NewBatchGenerator(&countingGenerator{}, application.BatchConfig{MaxSize: 1})
+
+		_, err := batches.GenerateWebPageReports(context.Background(), []string{"http://localhost/1", "http://localhost/2"}, model.ReportOptions{})
+
+		if !errors.Is(err, ports.ErrBatchTooLarge) {
+			t.Fatalf("Expected ErrBatchTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("should reject invalid options before generating any report", func(t *testing.T) {
+		generator := &countingGenerator{}
+		batches := application.NewBatchGenerator(generator, application.BatchConfig{})
+
+		_, err := batches.GenerateWebPageReports(context.Background(), []string{"http://localhost/1"}, model.ReportOptions{Analyzers: []string{"unknown"}})
+
+		if !errors.Is(err, domain.ErrUnknownAnalyzer) {
+			t.Fatalf("Expected ErrUnknownAnalyzer, got %v", err)
+		}
+		if max := generator.max.Load(); max != 0 {
+			t.Fatalf("Expected no report to be generated, got %d at the same time", max)
+		}
+	})
+
+	t.Run("should time out the reports that are not done by the deadline", func(t *testing.T) {
+		// Only the first report generated is done before the deadline, while the
+		// second one is interrupted and the third one never starts.
+		generator := &countingGenerator{delay: 60 * time.Millisecond}
+		batches := application.NewBatchGenerator(generator, application.BatchConfig{Concurrency: 1, Timeout: 100 * time.Millisecond})
+		locations := []string{"http://localhost/1", "http://localhost/2", "http://localhost/3"}
+
+		results, err := batches.GenerateWebPageReports(context.Background(), locations, model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		done, timedOut := 0, 0
+		for _, r := range results {
+			switch {
+			case r.Err == nil:
+				done++
+			case errors.Is(r.Err, domain.ErrTimeout):
+				timedOut++
+			}
+		}
+		if done != 1 || timedOut != 2 {
+			t.Fatalf("Expected 1 report to be done and 2 to time out, got %+v", results)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+const defaultJobWorkers = 4
+const defaultJobQueueSize = 100
+const defaultJobRetention = time.Hour
+
+type JobQueueConfig struct {
+	// Workers is the number of reports generated at the same time.
+	Workers int
+	// QueueSize is the number of jobs that can wait for a worker before new
+	// ones are rejected.
+	QueueSize int
+	// Retention is how long finished jobs can be retrieved for.
+	Retention time.Duration
+}
+
+// JobQueue generates reports in the background on a fixed number of workers.
+// Jobs are only kept in memory.
+type JobQueue struct {
+	generator ports.ReportGenerator
+	cfg       JobQueueConfig
+	queue     chan jobTask
+
+	mu   sync.RWMutex
+	jobs map[string]*model.ReportJob
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type jobTask struct {
+	id       string
+	location string
+	opts     model.ReportOptions
+}
+
+// NewJobQueue starts the workers of the queue. They run until Close is called.
+func NewJobQueue(generator ports.ReportGenerator, cfg JobQueueConfig) *JobQueue {
+	if cfg.Workers < 1 {
+		cfg.Workers = defaultJobWorkers
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = defaultJobQueueSize
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultJobRetention
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &JobQueue{
+		generator: generator,
+		cfg:       cfg,
+		queue:     make(chan jobTask, cfg.QueueSize),
+		jobs:      map[string]*model.ReportJob{},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	for range cfg.Workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// SubmitWebPageReport implements the JobService interface. The location and
+// the options are validated before the job is queued, so that obviously invalid
+// requests are rejected right away.
+func (q *JobQueue) SubmitWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.ReportJob, error) {
+	if err := domain.ValidateLocation(location); err != nil {
+		return model.ReportJob{}, fmt.Errorf("%w: %w", domain.ErrInvlidPage, err)
+	}
+	if err := q.generator.ValidateOptions(opts); err != nil {
+		return model.ReportJob{}, err
+	}
+
+	now := time.Now().UTC()
+	job := &model.ReportJob{
+		ID:        rand.Text(),
+		URL:       location,
+		State:     model.JobStateQueued,
+		CreatedAt: now,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.removeExpired(now)
+	select {
+	case q.queue <- jobTask{id: job.ID, location: location, opts: opts}:
+	default:
+		return model.ReportJob{}, ports.ErrQueueFull
+	}
+	q.jobs[job.ID] = job
+	return *job, nil
+}
+
+// GetReportJob implements the JobService interface.
+func (q *JobQueue) GetReportJob(ctx context.Context, id string) (model.ReportJob, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return model.ReportJob{}, fmt.Errorf("%w: %v", ports.ErrJobNotFound, id)
+	}
+	return *job, nil
+}
+
+// Close cancels the running jobs and waits for the workers to stop. Jobs that
+// are still queued are never run.
+func (q *JobQueue) Close() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *JobQueue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case task := <-q.queue:
+			q.run(task)
+		}
+	}
+}
+
+func (q *JobQueue) run(task jobTask) {
+	q.update(task.id, func(job *model.ReportJob) {
+		job.State = model.JobStateRunning
+		job.StartedAt = time.Now().UTC()
+	})
+	report, err := q.generator.GenerateWebPageReport(q.ctx, task.location, task.opts)
+	if err != nil {
+		// Clients only get the problem the error maps to, so its details are
+		// only ever logged here.
+		log.Printf("job %v for %v failed: %v", task.id, task.location, err)
+	}
+	q.update(task.id, func(job *model.ReportJob) {
+		job.FinishedAt = time.Now().UTC()
+		if err != nil {
+			job.State = model.JobStateFailed
+			job.Err = err
+			return
+		}
+		job.State = model.JobStateSucceeded
+		job.Report = report
+	})
+}
+
+func (q *JobQueue) update(id string, apply func(job *model.ReportJob)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job, ok := q.jobs[id]; ok {
+		apply(job)
+	}
+}
+
+// removeExpired must be called with the lock held.
+func (q *JobQueue) removeExpired(now time.Time) {
+	for id, job := range q.jobs {
+		if !job.FinishedAt.IsZero() && now.Sub(job.FinishedAt) > q.cfg.Retention {
+			delete(q.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/application"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// blockingGenerator blocks every report until release is closed. Options with
+// analyzers are invalid.
+type blockingGenerator struct {
+	release chan struct{}
+	err     error
+}
+
+func (g blockingGenerator) ValidateOptions(opts model.ReportOptions) error {
+	if len(opts.Analyzers) > 0 {
+		return domain.ErrUnknownAnalyzer
+	}
+	return nil
+}
+
+func (g blockingGenerator) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return model.WebPageReport{}, ctx.Err()
+	}
+	return model.WebPageReport{URL: location}, g.err
+}
+
+func waitForState(t *testing.T, q *application.JobQueue, id string, state model.JobState) model.ReportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := q.GetReportJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job to be %v, got %v", state, job.State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobQueue(t *testing.T) {
+	t.Parallel()
+	t.Run("should go through every state until it succeeds", func(t *testing.T) {
+		release := make(chan struct{})
+		q := application.NewJobQueue(blockingGenerator{release: release}, application.JobQueueConfig{Workers: 1})
+		defer q.Close()
+
+		first, err := q.SubmitWebPageReport(context.Background(), "http://localhost/1", model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		second, err := q.SubmitWebPageReport(context.Background(), "http://localhost/2", model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		waitForState(t, q, first.ID, model.JobStateRunning)
+		if job, _ := q.GetReportJob(context.Background(), second.ID); job.State != model.JobStateQueued {
+			t.Fatalf("Expected second job to wait for the only worker, got %v", job.State)
+		}
+		close(release)
+		job := waitForState(t, q, second.ID, model.JobStateSucceeded)
+		if job.Report.URL != "http://localhost/2" || job.FinishedAt.IsZero() {
+			t.Fatalf("Unexpected job: %+v", job)
+		}
+	})
+
+	t.Run("should keep the error of failed jobs", func(t *testing.T) {
+		release := make(chan struct{})
+		close(release)
+		q := application.NewJobQueue(blockingGenerator{release: release, err: domain.ErrAnalysisFailed}, application.JobQueueConfig{})
+		defer q.Close()
+
+		job, err := q.SubmitWebPageReport(context.Background(), "http://localhost", model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		job = waitForState(t, q, job.ID, model.JobStateFailed)
+		if !errors.Is(job.Err, domain.ErrAnalysisFailed) {
+			t.Fatalf("Expected ErrAnalysisFailed, got %v", job.Err)
+		}
+	})
+
+	t.Run("should reject jobs when the queue is full", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		q := application.NewJobQueue(blockingGenerator{release: release}, application.JobQueueConfig{Workers: 1, QueueSize: 1})
+		defer q.Close()
+
+		running, _ := q.SubmitWebPageReport(context.Background(), "http://localhost/1", model.ReportOptions{})
+		waitForState(t, q, running.ID, model.JobStateRunning)
+		if _, err := q.SubmitWebPageReport(context.Background(), "http://localhost/2", model.ReportOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err := q.SubmitWebPageReport(context.Background(), "http://localhost/3", model.ReportOptions{})
+
+		if !errors.Is(err, ports.ErrQueueFull) {
+			t.Fatalf("Expected ErrQueueFull, got %v", err)
+		}
+	})
+
+	t.Run("should reject invalid URLs right away", func(t *testing.T) {
+		q := application.NewJobQueue(blockingGenerator{}, application.JobQueueConfig{})
+		defer q.Close()
+
+		_, err := q.SubmitWebPageReport(context.Background(), "not a url", model.ReportOptions{})
+
+		if !errors.Is(err, domain.ErrInvalidURL) {
+			t.Fatalf("Expected ErrInvalidURL, got %v", err)
+		}
+	})
+
+	t.Run("should reject invalid options right away", func(t *testing.T) {
+		q := application.NewJobQueue(blockingGenerator{}, application.JobQueueConfig{})
+		defer q.Close()
+
+		_, err := q.SubmitWebPageReport(context.Background(), "http://localhost", model.ReportOptions{Analyzers: []string{"unknown"}})
+
+		if !errors.Is(err, domain.ErrUnknownAnalyzer) {
+			t.Fatalf("Expected ErrUnknownAnalyzer, got %v", err)
+		}
+	})
+
+	t.Run("should return not found for unknown jobs", func(t *testing.T) {
+		q := application.NewJobQueue(blockingGenerator{}, application.JobQueueConfig{})
+		defer q.Close()
+
+		_, err := q.GetReportJob(context.Background(), "unknown")
+
+		if !errors.Is(err, ports.ErrJobNotFound) {
+			t.Fatalf("Expected ErrJobNotFound, got %v", err)
+		}
+	})
+}
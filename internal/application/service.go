@@ -1,15 +1,52 @@
 package application
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
 	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
 	"github.com/G-Fuchter/home24-assignment/internal/ports"
 )
 
 // Application service seems redundant on such a small project, but it will help with scaling in the future
 type Service struct {
-	domainService ports.Service
+	domainService ports.ReportGenerator
+	reports       ports.ReportRepository
+}
+
+func NewService(domainService ports.ReportGenerator, reports ports.ReportRepository) *Service {
+	return &Service{
+		domainService: domainService,
+		reports:       reports,
+	}
+}
+
+// GenerateWebPageReport generates a report and stores it, so that it can be
+// retrieved later by its ID.
+func (s *Service) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	report, err := s.domainService.GenerateWebPageReport(ctx, location, opts)
+	if err != nil {
+		return model.WebPageReport{}, err
+	}
+	report.ID = rand.Text()
+	report.CreatedAt = time.Now().UTC()
+	if err := s.reports.Save(ctx, report); err != nil {
+		return model.WebPageReport{}, fmt.Errorf("failed to save report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *Service) GetWebPageReport(ctx context.Context, id string) (model.WebPageReport, error) {
+	return s.reports.FindByID(ctx, id)
+}
+
+func (s *Service) ListWebPageReports(ctx context.Context, url string) ([]model.WebPageReport, error) {
+	return s.reports.FindByURL(ctx, url)
 }
 
-func (s *Service) GenerateWebPageReport(location string) (model.WebPageReport, error) {
-	return s.domainService.GenerateWebPageReport(location)
+// ValidateOptions implements the ReportGenerator interface.
+func (s *Service) ValidateOptions(opts model.ReportOptions) error {
+	return s.domainService.ValidateOptions(opts)
 }
@@ -0,0 +1,63 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/repository"
+	"github.com/G-Fuchter/home24-assignment/internal/application"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+type stubGenerator struct {
+	report model.WebPageReport
+	err    error
+}
+
+func (g stubGenerator) ValidateOptions(opts model.ReportOptions) error {
+	return nil
+}
+
+func (g stubGenerator) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	return g.report, g.err
+}
+
+func TestGenerateWebPageReport(t *testing.T) {
+	t.Parallel()
+	t.Run("should store the generated report", func(t *testing.T) {
+		reports := repository.NewMemoryReportRepository(repository.MemoryConfig{})
+		service := application.NewService(stubGenerator{report: model.WebPageReport{URL: "http://localhost"}}, reports)
+
+		report, err := service.GenerateWebPageReport(context.Background(), "http://localhost", model.ReportOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if report.ID == "" || report.CreatedAt.IsZero() {
+			t.Fatalf("Expected ID and creation time to be set, got %+v", report)
+		}
+		stored, err := service.GetWebPageReport(context.Background(), report.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stored.ID != report.ID {
+			t.Fatalf("Expected stored report %v, got %v", report.ID, stored.ID)
+		}
+	})
+
+	t.Run("should not store failed reports", func(t *testing.T) {
+		reports := repository.NewMemoryReportRepository(repository.MemoryConfig{})
+		service := application.NewService(stubGenerator{err: errors.New("failed")}, reports)
+
+		_, err := service.GenerateWebPageReport(context.Background(), "http://localhost", model.ReportOptions{})
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+
+		all, _ := service.ListWebPageReports(context.Background(), "")
+		if len(all) != 0 {
+			t.Fatalf("Expected no stored reports, got %v", len(all))
+		}
+	})
+}
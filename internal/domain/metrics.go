@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// metric runs a single analyzer. Analyzers do not depend on each other, so one
+// of them failing does not prevent the others from being run.
+type metric struct {
+	analyzer ports.Analyzer
+	// stage is reported as complete once all of its metrics are computed.
+	stage model.ReportStage
+}
+
+func newMetric(a ports.Analyzer) metric {
+	stage := model.StageAnalyses
+	if staged, ok := a.(ports.StagedAnalyzer); ok {
+		stage = staged.Stage()
+	}
+	return metric{analyzer: a, stage: stage}
+}
+
+func (m metric) field() string {
+	return m.analyzer.Name()
+}
+
+// compute runs the analyzer on document.
+func (m metric) compute(ctx context.Context, document ports.Document) (ports.Result, error) {
+	return m.analyzer.Analyze(ctx, document)
+}
+
+func (m metric) warning(err error) model.FieldWarning {
+	status := model.FieldStatusFailed
+	switch {
+	case errors.Is(err, ports.ErrNotFound):
+		status = model.FieldStatusMissing
+	case errors.Is(err, ports.ErrNotStated):
+		status = model.FieldStatusUnknown
+	}
+	return model.FieldWarning{
+		Field:   m.field(),
+		Status:  status,
+		Message: err.Error(),
+	}
+}
+
+// analyzerMetrics returns a metric per analyzer, in the same order.
+func analyzerMetrics(analyzers []ports.Analyzer) []metric {
+	metrics := make([]metric, 0, len(analyzers))
+	for _, a := range analyzers {
+		metrics = append(metrics, newMetric(a))
+	}
+	return metrics
+}
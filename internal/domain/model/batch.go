@@ -0,0 +1,9 @@
+package model
+
+// BatchResult is the outcome of a single URL of a batch. Exactly one of Report
+// and Err is set.
+type BatchResult struct {
+	URL    string
+	Report WebPageReport
+	Err    error
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// FetchOptions changes how the page of a report, and the links it contains, are
+// downloaded. Empty fields fall back to the defaults of the fetcher.
+type FetchOptions struct {
+	Timeout   time.Duration
+	UserAgent string
+	// Headers are added to every request, replacing the default ones with the
+	// same name.
+	Headers map[string]string
+	// Cookies are sent by name.
+	Cookies     map[string]string
+	BasicAuth   *BasicAuth
+	BearerToken string
+	// Proxy is the URL of the HTTP proxy requests are sent through.
+	Proxy string
+	// MaxRedirects is the number of redirects followed before giving up, which
+	// cannot exceed the limit of the server. Nil uses that limit, and 0 follows
+	// none.
+	MaxRedirects *int
+}
+
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// WithoutCredentials returns a copy of the options without headers, cookies or
+// authentication, for requests to hosts they were not meant for.
+func (o FetchOptions) WithoutCredentials() FetchOptions {
+	return FetchOptions{
+		Timeout:      o.Timeout,
+		UserAgent:    o.UserAgent,
+		Proxy:        o.Proxy,
+		MaxRedirects: o.MaxRedirects,
+	}
+}
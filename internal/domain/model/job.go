@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type JobState string
+
+const (
+	JobStateQueued    JobState = "queued"
+	JobStateRunning   JobState = "running"
+	JobStateSucceeded JobState = "succeeded"
+	JobStateFailed    JobState = "failed"
+)
+
+// ReportJob is a report that is generated in the background.
+type ReportJob struct {
+	ID         string
+	URL        string
+	State      JobState
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Report is only set once the job has succeeded.
+	Report WebPageReport
+	// Err is only set once the job has failed.
+	Err error
+}
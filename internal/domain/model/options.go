@@ -0,0 +1,16 @@
+package model
+
+// ReportOptions changes how a single report is generated.
+type ReportOptions struct {
+	// Partial makes the report succeed even when some of its fields cannot be
+	// computed. Those fields are listed in the report's warnings.
+	Partial bool
+	// Analyzers are the names of the optional analyzers to run on top of the
+	// built-in ones. Nil runs all of them, while an empty slice runs none.
+	Analyzers []string
+	// Fetch changes how the page is downloaded.
+	Fetch FetchOptions
+	// Progress, when set, is called with each stage of the report as it
+	// completes. It is never called concurrently nor after the report is returned.
+	Progress func(stage ReportStage)
+}
@@ -0,0 +1,15 @@
+package model
+
+// ReportStage is a step of the generation of a report.
+type ReportStage string
+
+const (
+	StageDownloaded ReportStage = "downloaded"
+	StageParsed     ReportStage = "parsed"
+	StageDocument   ReportStage = "document"
+	StageTitle      ReportStage = "title"
+	StageLinks      ReportStage = "links"
+	StageLinkChecks ReportStage = "linkChecks"
+	StageHeadings   ReportStage = "headings"
+	StageAnalyses   ReportStage = "analyses"
+)
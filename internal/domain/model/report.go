@@ -1,6 +1,30 @@
 package model
 
+import "time"
+
 type WebPageReport struct {
+	// ID and CreatedAt are only set once the report has been stored.
+	ID        string
+	URL       string
+	CreatedAt time.Time
+
+	// FinalURL is the URL the page was served from once every redirect has
+	// been followed, and the one its links are resolved against.
+	FinalURL string
+	// Redirects lists the redirects followed to get to FinalURL, in order.
+	Redirects []Redirect
+
+	// ResponseInfo describes the response the page was served with. It is nil
+	// for documents that were not downloaded, such as local files.
+	ResponseInfo *ResponseInfo
+	// TLS describes the connection of pages served over HTTPS, and is nil for
+	// the rest.
+	TLS *TLSInfo
+
+	// Charset is the encoding the page was served in. The rest of the report
+	// is always in UTF-8.
+	Charset string
+
 	DocumentVersion   string
 	Title             string
 	ExternalLinkCount int
@@ -12,4 +36,101 @@ type WebPageReport struct {
 	HeaderFourCount   int
 	HeaderFiveCount   int
 	HeaderSixCount    int
+
+	InaccessibleLinkCount int
+	InaccessibleLinks     []InaccessibleLink
+
+	// Analyses holds the result of every analyzer that was run, by analyzer name.
+	Analyses map[string]any
+
+	// Warnings lists the fields that could not be computed in a partial report.
+	Warnings []FieldWarning
+}
+
+// SetAnalysis stores the result of the analyzer called name.
+func (r *WebPageReport) SetAnalysis(name string, result any) {
+	if r.Analyses == nil {
+		r.Analyses = map[string]any{}
+	}
+	r.Analyses[name] = result
+}
+
+// InaccessibleLink is a link that either answered with an error status code or
+// could not be reached at all, in which case Error holds the reason.
+type InaccessibleLink struct {
+	URL        string
+	StatusCode int
+	Error      string
+}
+
+// Redirect is a response that redirected to another URL.
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Location   string
+}
+
+// ResponseInfo describes the final response of a download, once every
+// redirect has been followed.
+type ResponseInfo struct {
+	StatusCode int
+	// Protocol is the HTTP version, such as "HTTP/1.1" or "HTTP/2.0".
+	Protocol string
+	// TimeToFirstByte is the time from the first request until the headers of
+	// the final response were received. TotalTime includes reading the body.
+	// Both include the redirects.
+	TimeToFirstByte time.Duration
+	TotalTime       time.Duration
+	// ContentLength is the length the response declared, or -1 when it did not
+	// declare one. BodySize is the number of bytes read, once decompressed.
+	ContentLength int64
+	BodySize      int64
+	// Compression is the content encoding the body was served with, such as
+	// "gzip", or empty when it was not compressed.
+	Compression  string
+	Server       string
+	CacheControl string
+	LastModified string
+	ETag         string
+}
+
+// TLSInfo describes the connection a page was served over and the certificates
+// its server presented, starting with its own.
+type TLSInfo struct {
+	// Version is the name of the protocol version, such as "TLS 1.3".
+	Version      string
+	CipherSuite  string
+	Certificates []Certificate
+	// Warnings lists the certificates that expire soon.
+	Warnings []string
+}
+
+type Certificate struct {
+	Subject string
+	Issuer  string
+	// SANs are the DNS names and IP addresses the certificate is valid for.
+	SANs      []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// DaysRemaining is the number of whole days the certificate was still
+	// valid for when the page was downloaded, or 0 when it had expired.
+	DaysRemaining int
+}
+
+type FieldStatus string
+
+const (
+	// FieldStatusMissing means the page does not contain the element the field is computed from.
+	FieldStatusMissing FieldStatus = "missing"
+	// FieldStatusUnknown means the page does not state the value of the field.
+	FieldStatusUnknown FieldStatus = "unknown"
+	// FieldStatusFailed means the field could not be computed for any other reason.
+	FieldStatusFailed FieldStatus = "failed"
+)
+
+// FieldWarning explains why a field of a partial report has been left empty.
+type FieldWarning struct {
+	Field   string
+	Status  FieldStatus
+	Message string
 }
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"sync"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// progress reports each stage of a report once all of its metrics are
+// computed. It is safe for concurrent use, but never calls report concurrently.
+// A nil progress reports nothing.
+type progress struct {
+	mu      sync.Mutex
+	report  func(stage model.ReportStage)
+	pending map[model.ReportStage]int
+}
+
+func newProgress(report func(stage model.ReportStage)) *progress {
+	if report == nil {
+		return nil
+	}
+	return &progress{
+		report:  report,
+		pending: map[model.ReportStage]int{},
+	}
+}
+
+// expect registers the metrics that have to be done before their stages are
+// reported.
+func (p *progress) expect(metrics []metric) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, m := range metrics {
+		p.pending[m.stage]++
+	}
+}
+
+// done marks a metric of stage as computed, reporting the stage when it was
+// the last one.
+func (p *progress) done(stage model.ReportStage) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[stage]--
+	if p.pending[stage] == 0 {
+		p.report(stage)
+	}
+}
+
+// complete reports a stage that has no metrics.
+func (p *progress) complete(stage model.ReportStage) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.report(stage)
+}
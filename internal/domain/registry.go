@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+var ErrUnknownAnalyzer = errors.New("analyzer does not exist")
+
+// Registry holds the analyzers that fill reports. Built-in analyzers always run,
+// while the rest are selected by name for every report.
+type Registry struct {
+	builtIn   []ports.Analyzer
+	analyzers []ports.Analyzer
+	byName    map[string]ports.Analyzer
+}
+
+func NewRegistry(builtIn []ports.Analyzer, analyzers ...ports.Analyzer) (*Registry, error) {
+	r := &Registry{
+		byName: map[string]ports.Analyzer{},
+	}
+	for _, a := range builtIn {
+		if err := r.RegisterBuiltIn(a); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range analyzers {
+		if err := r.Register(a); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds an analyzer that reports can select.
+func (r *Registry) Register(a ports.Analyzer) error {
+	if err := r.add(a); err != nil {
+		return err
+	}
+	r.analyzers = append(r.analyzers, a)
+	return nil
+}
+
+// RegisterBuiltIn adds an analyzer that runs for every report.
+func (r *Registry) RegisterBuiltIn(a ports.Analyzer) error {
+	if err := r.add(a); err != nil {
+		return err
+	}
+	r.builtIn = append(r.builtIn, a)
+	return nil
+}
+
+func (r *Registry) add(a ports.Analyzer) error {
+	if _, ok := r.byName[a.Name()]; ok {
+		return fmt.Errorf("analyzer %q is already registered", a.Name())
+	}
+	r.byName[a.Name()] = a
+	return nil
+}
+
+func (r *Registry) isBuiltIn(name string) bool {
+	return slices.ContainsFunc(r.builtIn, func(a ports.Analyzer) bool {
+		return a.Name() == name
+	})
+}
+
+// Names returns the names of the analyzers that can be selected, in
+// registration order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.analyzers))
+	for _, a := range r.analyzers {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+// Select returns the built-in analyzers followed by the ones with the given
+// names. A nil slice selects every registered analyzer, while an empty one
+// selects only the built-in ones. Built-in analyzers cannot be selected by name.
+func (r *Registry) Select(names []string) ([]ports.Analyzer, error) {
+	selected := slices.Clone(r.builtIn)
+	if names == nil {
+		return append(selected, r.analyzers...), nil
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		a, ok := r.byName[name]
+		if !ok || r.isBuiltIn(name) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownAnalyzer, name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, a)
+		}
+	}
+	return selected, nil
+}
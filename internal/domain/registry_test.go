@@ -0,0 +1,157 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+type stubAnalyzer struct {
+	name   string
+	result any
+	err    error
+}
+
+func (a stubAnalyzer) Name() string {
+	return a.name
+}
+
+func (a stubAnalyzer) Analyze(ctx context.Context, document ports.Document) (ports.Result, error) {
+	return stubResult{a.name, a.result}, a.err
+}
+
+// stubResult stores value as the analysis of the analyzer called name.
+type stubResult struct {
+	name  string
+	value any
+}
+
+func (r stubResult) Apply(report *model.WebPageReport) {
+	report.SetAnalysis(r.name, r.value)
+}
+
+func TestNewRegistry(t *testing.T) {
+	t.Parallel()
+	t.Run("should reject analyzers with the same name", func(t *testing.T) {
+		_, err := domain.NewRegistry(nil, stubAnalyzer{name: "a"}, stubAnalyzer{name: "a"})
+
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+
+	t.Run("should reject optional analyzers with the name of a built-in one", func(t *testing.T) {
+		_, err := domain.NewRegistry([]ports.Analyzer{stubAnalyzer{name: "a"}}, stubAnalyzer{name: "a"})
+
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+
+	t.Run("should keep registration order", func(t *testing.T) {
+		r, err := domain.NewRegistry(nil, stubAnalyzer{name: "b"}, stubAnalyzer{name: "a"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if names := r.Names(); !slices.Equal(names, []string{"b", "a"}) {
+			t.Fatalf("Expected [b a], got %v", names)
+		}
+	})
+}
+
+func TestRegistrySelect(t *testing.T) {
+	t.Parallel()
+	r, err := domain.NewRegistry(nil, stubAnalyzer{name: "a"}, stubAnalyzer{name: "b"}, stubAnalyzer{name: "c"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{name: "nil selects all", names: nil, expected: []string{"a", "b", "c"}},
+		{name: "empty selects none", names: []string{}, expected: []string{}},
+		{name: "selects by name", names: []string{"c", "a"}, expected: []string{"c", "a"}},
+		{name: "ignores duplicates", names: []string{"b", "b"}, expected: []string{"b"}},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			selected, err := r.Select(tcase.names)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			actual := []string{}
+			for _, a := range selected {
+				actual = append(actual, a.Name())
+			}
+			if !slices.Equal(actual, tcase.expected) {
+				t.Fatalf("Expected %v, got %v", tcase.expected, actual)
+			}
+		})
+	}
+
+	t.Run("should return error for unknown analyzers", func(t *testing.T) {
+		_, err := r.Select([]string{"a", "unknown"})
+
+		if !errors.Is(err, domain.ErrUnknownAnalyzer) {
+			t.Fatalf("Expected ErrUnknownAnalyzer, got %v", err)
+		}
+	})
+}
+
+func TestRegistrySelect_BuiltIn(t *testing.T) {
+	t.Parallel()
+	r, err := domain.NewRegistry([]ports.Analyzer{stubAnalyzer{name: "x"}, stubAnalyzer{name: "y"}}, stubAnalyzer{name: "a"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{name: "nil selects all", names: nil, expected: []string{"x", "y", "a"}},
+		{name: "empty selects the built-in ones", names: []string{}, expected: []string{"x", "y"}},
+		{name: "selects by name", names: []string{"a"}, expected: []string{"x", "y", "a"}},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			selected, err := r.Select(tcase.names)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			actual := []string{}
+			for _, a := range selected {
+				actual = append(actual, a.Name())
+			}
+			if !slices.Equal(actual, tcase.expected) {
+				t.Fatalf("Expected %v, got %v", tcase.expected, actual)
+			}
+		})
+	}
+
+	t.Run("should only name the optional analyzers", func(t *testing.T) {
+		if names := r.Names(); !slices.Equal(names, []string{"a"}) {
+			t.Fatalf("Expected [a], got %v", names)
+		}
+	})
+
+	t.Run("should not select built-in analyzers by name", func(t *testing.T) {
+		_, err := r.Select([]string{"x"})
+
+		if !errors.Is(err, domain.ErrUnknownAnalyzer) {
+			t.Fatalf("Expected ErrUnknownAnalyzer, got %v", err)
+		}
+	})
+}
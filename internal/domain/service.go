@@ -1,92 +1,236 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
 	"github.com/G-Fuchter/home24-assignment/internal/ports"
 )
 
 var ErrInvlidPage = errors.New("URL is invalid or unreachable")
+var ErrInvalidURL = errors.New("URL must be an absolute http or https URL")
+var ErrTimeout = errors.New("report could not be generated in time")
+var ErrAnalysisFailed = errors.New("page could not be analysed")
+
+type Config struct {
+	// Timeout is the deadline for generating a whole report, download included.
+	// Zero means there is no deadline other than the one of the caller's context.
+	Timeout time.Duration
+	// Concurrency is the maximum number of analyzers run at the same time for
+	// a single report. Zero computes all of them at once.
+	Concurrency int
+}
 
 type Service struct {
-	parser ports.DocumentParser
+	parser    ports.DocumentParser
+	analyzers *Registry
+	cfg       Config
 }
 
-func NewService(p ports.DocumentParser) *Service {
+// NewService returns a Service that fills reports with the analyzers of r. A
+// nil registry runs none, leaving every field of the reports empty.
+func NewService(p ports.DocumentParser, r *Registry, cfg Config) *Service {
+	if r == nil {
+		r, _ = NewRegistry(nil)
+	}
 	return &Service{
-		parser: p,
+		parser:    p,
+		analyzers: r,
+		cfg:       cfg,
 	}
 }
 
-func (s *Service) GenerateWebPageReport(location string) (model.WebPageReport, error) {
+// GenerateWebPageReport downloads and analyses the page at location. By default
+// the report fails as soon as a single analyzer fails; when opts.Partial is set
+// those analyzers are reported as warnings instead.
+func (s *Service) GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error) {
+	return s.withDeadline(ctx, func(ctx context.Context) (model.WebPageReport, error) {
+		if err := ValidateLocation(location); err != nil {
+			return model.WebPageReport{}, fmt.Errorf("%w: %w", ErrInvlidPage, err)
+		}
+		analyzers, err := s.analyzers.Select(opts.Analyzers)
+		if err != nil {
+			return model.WebPageReport{}, err
+		}
+
+		document, err := s.parser.DownloadDocument(ctx, location, opts.Fetch)
+		if err != nil {
+			return model.WebPageReport{}, fmt.Errorf("%w: %w", ErrInvlidPage, err)
+		}
+		// The parser parses the document while downloading it, so both stages
+		// complete at the same time.
+		progress := newProgress(opts.Progress)
+		progress.complete(model.StageDownloaded)
+		progress.complete(model.StageParsed)
+
+		report, err := s.analyzeDocument(ctx, document, analyzers, progress, opts)
+		if err != nil {
+			return model.WebPageReport{}, err
+		}
+		// Reports are listed by the URL they were requested for, wherever it
+		// redirected to.
+		report.URL = location
+		return report, nil
+	})
+}
 
-	if err := s.parser.DownloadDocument(location); err != nil {
-		return model.WebPageReport{}, fmt.Errorf("%w: %w", ErrInvlidPage, err)
+// ValidateOptions returns an error wrapping ErrUnknownAnalyzer or
+// ports.ErrInvalidFetchOptions when a report cannot be generated with opts.
+func (s *Service) ValidateOptions(opts model.ReportOptions) error {
+	if _, err := s.analyzers.Select(opts.Analyzers); err != nil {
+		return err
 	}
+	return ValidateFetchOptions(opts.Fetch)
+}
 
-	documentVersion, err := s.parser.GetDocumentVersion()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get document version: %w", err)
-	}
+// GenerateDocumentReport analyses a document that has already been parsed, such
+// as one read from a file, in the same way GenerateWebPageReport does.
+func (s *Service) GenerateDocumentReport(ctx context.Context, document ports.Document, opts model.ReportOptions) (model.WebPageReport, error) {
+	return s.withDeadline(ctx, func(ctx context.Context) (model.WebPageReport, error) {
+		analyzers, err := s.analyzers.Select(opts.Analyzers)
+		if err != nil {
+			return model.WebPageReport{}, err
+		}
+		return s.analyzeDocument(ctx, document, analyzers, newProgress(opts.Progress), opts)
+	})
+}
 
-	title, err := s.parser.GetTitle()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get title: %w", err)
+// withDeadline runs generate with the configured timeout, and makes sure that
+// reports cut short by the deadline or by cancellation are never returned.
+func (s *Service) withDeadline(ctx context.Context, generate func(ctx context.Context) (model.WebPageReport, error)) (model.WebPageReport, error) {
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
 	}
 
-	externalLinkCount, err := s.parser.GetExternalLinkCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get external link count: %w", err)
+	report, err := generate(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return model.WebPageReport{}, fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
 	}
-
-	internalLinkCount, err := s.parser.GetInternalLinkCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get internal link count: %w", err)
+	if err == nil {
+		err = ctx.Err()
 	}
-
-	containsLogin, err := s.parser.GetContainsLogin()
 	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to check login form: %w", err)
+		return model.WebPageReport{}, err
 	}
+	return report, nil
+}
 
-	headerOneCount, err := s.parser.GetHeaderOneCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get H1 count: %w", err)
+func (s *Service) analyzeDocument(ctx context.Context, document ports.Document, analyzers []ports.Analyzer, progress *progress, opts model.ReportOptions) (model.WebPageReport, error) {
+	report := model.WebPageReport{
+		URL:          document.URL(),
+		FinalURL:     document.URL(),
+		Redirects:    document.Redirects(),
+		ResponseInfo: document.ResponseInfo(),
+		TLS:          document.TLS(),
+		Charset:      document.Charset(),
 	}
-
-	headerTwoCount, err := s.parser.GetHeaderTwoCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get H2 count: %w", err)
+	metrics := analyzerMetrics(analyzers)
+	progress.expect(metrics)
+	results, errs := s.computeMetrics(ctx, document, metrics, !opts.Partial, progress)
+	if !opts.Partial {
+		if i := firstFailure(errs); i >= 0 {
+			return model.WebPageReport{}, fmt.Errorf("%w: failed to get %v: %w", ErrAnalysisFailed, metrics[i].field(), errs[i])
+		}
+	}
+	// Results are applied in the order of the analyzers, so that reports do not
+	// depend on which analyzer finished first.
+	for i, err := range errs {
+		if err != nil {
+			report.Warnings = append(report.Warnings, metrics[i].warning(err))
+		} else if results[i] != nil {
+			results[i].Apply(&report)
+		}
 	}
 
-	headerThreeCount, err := s.parser.GetHeaderThreeCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get H3 count: %w", err)
+	return report, nil
+}
+
+// computeMetrics runs the metrics concurrently, at most Config.Concurrency at a
+// time, and returns the result and the error of each one in the same order.
+// When failFast is set, the remaining metrics are cancelled as soon as one of
+// them fails, and the stages of failed metrics are never reported to progress.
+func (s *Service) computeMetrics(ctx context.Context, document ports.Document, metrics []metric, failFast bool, progress *progress) ([]ports.Result, []error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := s.cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = len(metrics)
+	}
+	sem := make(chan struct{}, concurrency)
+	results := make([]ports.Result, len(metrics))
+	errs := make([]error, len(metrics))
+	var wg sync.WaitGroup
+	for i, m := range metrics {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			results[i], errs[i] = m.compute(ctx, document)
+			if errs[i] != nil && failFast {
+				cancel()
+				return
+			}
+			progress.done(m.stage)
+		}()
 	}
+	wg.Wait()
+	return results, errs
+}
 
-	headerFourCount, err := s.parser.GetHeaderFourCount()
-	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get H4 count: %w", err)
+// firstFailure returns the index of the first error that caused the metrics to
+// be cancelled, rather than one of the cancellations themselves, or -1 when
+// there are no errors.
+func firstFailure(errs []error) int {
+	first := -1
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
 	}
+	return first
+}
 
-	headerFiveCount, err := s.parser.GetHeaderFiveCount()
+// ValidateLocation returns an error wrapping ErrInvalidURL when location is not
+// a URL that reports can be generated for.
+func ValidateLocation(location string) error {
+	u, err := url.Parse(location)
 	if err != nil {
-		return model.WebPageReport{}, fmt.Errorf("failed to get H5 count: %w", err)
-	}
-
-	return model.WebPageReport{
-		DocumentVersion:   documentVersion,
-		Title:             title,
-		ExternalLinkCount: externalLinkCount,
-		InternalLinkCount: internalLinkCount,
-		ContainsLogin:     containsLogin,
-		HeaderOneCount:    headerOneCount,
-		HeaderTwoCount:    headerTwoCount,
-		HeaderThreeCount:  headerThreeCount,
-		HeaderFourCount:   headerFourCount,
-		HeaderFiveCount:   headerFiveCount,
-	}, err
+		return fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, location)
+	}
+	return nil
+}
 
+// ValidateFetchOptions returns an error wrapping ports.ErrInvalidFetchOptions
+// when opts cannot be used to download a page.
+func ValidateFetchOptions(opts model.FetchOptions) error {
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("%w: proxy %q is not a valid URL", ports.ErrInvalidFetchOptions, opts.Proxy)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,361 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/fetcher"
+	"github.com/G-Fuchter/home24-assignment/internal/adapters/parser"
+	"github.com/G-Fuchter/home24-assignment/internal/domain"
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"github.com/G-Fuchter/home24-assignment/internal/ports"
+)
+
+// newService returns a Service that downloads pages and fills reports with the
+// built-in analyzers, followed by analyzers.
+func newService(t testing.TB, cfg domain.Config, analyzers ...ports.Analyzer) *domain.Service {
+	t.Helper()
+	pageFetcher := fetcher.NewHTTPFetcher(fetcher.Config{})
+	registry, err := domain.NewRegistry(parser.BuiltInAnalyzers(pageFetcher), analyzers...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return domain.NewService(parser.NewWebPageParser(pageFetcher, parser.Config{}), registry, cfg)
+}
+
+// TestGenerateWebPageReport_Concurrent is meant to be run with -race. Every
+// page has a different title and number of headers, so any report that mixes
+// up documents between requests is detected.
+func TestGenerateWebPageReport_Concurrent(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(r.URL.Path, "/page/%d", &n)
+		fmt.Fprintf(
+			w,
+			"<!DOCTYPE html><html><head><title>Page %d</title></head><body>%v<a href=\"/page/%d\"></a></body></html>",
+			n, strings.Repeat("<h2>Header</h2>", n), n,
+		)
+	}))
+	defer srv.Close()
+
+	service := newService(t, domain.Config{})
+
+	const requests = 50
+	var wg sync.WaitGroup
+	for n := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := service.GenerateWebPageReport(context.Background(), fmt.Sprintf("%v/page/%d", srv.URL, n), model.ReportOptions{})
+			if err != nil {
+				t.Errorf("Unexpected error for page %d: %v", n, err)
+				return
+			}
+			if expected := fmt.Sprintf("Page %d", n); report.Title != expected {
+				t.Errorf("Expected title %q, got %q", expected, report.Title)
+			}
+			if report.HeaderTwoCount != n {
+				t.Errorf("Expected H2 count %d for page %d, got %d", n, n, report.HeaderTwoCount)
+			}
+			if report.InternalLinkCount != 1 || report.InaccessibleLinkCount != 0 {
+				t.Errorf("Unexpected link counts for page %d: %+v", n, report)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGenerateWebPageReport_Deadline(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	t.Run("should return timeout error when deadline is exceeded", func(t *testing.T) {
+		service := newService(t, domain.Config{Timeout: 50 * time.Millisecond})
+
+		_, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{})
+
+		if !errors.Is(err, domain.ErrTimeout) {
+			t.Fatalf("Expected ErrTimeout, got %v", err)
+		}
+	})
+
+	t.Run("should stop when caller cancels", func(t *testing.T) {
+		service := newService(t, domain.Config{})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := service.GenerateWebPageReport(ctx, srv.URL, model.ReportOptions{})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if errors.Is(err, domain.ErrTimeout) {
+			t.Fatalf("Cancellation should not be reported as a timeout: %v", err)
+		}
+	})
+}
+
+func TestGenerateWebPageReport_InvalidURL(t *testing.T) {
+	t.Parallel()
+	tests := []string{"", "home24.de", "ftp://home24.de", "http://", "://home24.de"}
+
+	service := newService(t, domain.Config{})
+	for _, location := range tests {
+		t.Run(location, func(t *testing.T) {
+			_, err := service.GenerateWebPageReport(context.Background(), location, model.ReportOptions{})
+
+			if !errors.Is(err, domain.ErrInvalidURL) {
+				t.Fatalf("Expected ErrInvalidURL, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateWebPageReport_Partial(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body><h1>Header</h1><h6>Header</h6></body></html>")
+	}))
+	defer srv.Close()
+	service := newService(t, domain.Config{})
+
+	t.Run("should fail on the first missing field by default", func(t *testing.T) {
+		_, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{})
+
+		if !errors.Is(err, domain.ErrAnalysisFailed) {
+			t.Fatalf("Expected ErrAnalysisFailed, got %v", err)
+		}
+	})
+
+	t.Run("should report missing fields as warnings in partial mode", func(t *testing.T) {
+		report, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{Partial: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if report.HeaderOneCount != 1 || report.HeaderSixCount != 1 {
+			t.Errorf("Expected the fields that could be computed to be set, got %+v", report)
+		}
+		expected := map[string]model.FieldStatus{
+			"documentVersion": model.FieldStatusUnknown,
+			"title":           model.FieldStatusMissing,
+		}
+		if len(report.Warnings) != len(expected) {
+			t.Fatalf("Expected %v warnings, got %+v", len(expected), report.Warnings)
+		}
+		for _, w := range report.Warnings {
+			if expected[w.Field] != w.Status {
+				t.Errorf("Expected %v to be %v, got %v", w.Field, expected[w.Field], w.Status)
+			}
+		}
+	})
+}
+
+func TestGenerateWebPageReport_Analyzers(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<!DOCTYPE html><html><head><title>Test</title></head></html>")
+	}))
+	defer srv.Close()
+
+	service := newService(t, domain.Config{},
+		stubAnalyzer{name: "ok", result: 42},
+		stubAnalyzer{name: "failing", err: errors.New("analyzer failed")},
+	)
+
+	t.Run("should include the results of the selected analyzers", func(t *testing.T) {
+		report, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{Analyzers: []string{"ok"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(report.Analyses) != 1 || report.Analyses["ok"] != 42 {
+			t.Fatalf("Expected only the ok analyzer result, got %v", report.Analyses)
+		}
+	})
+
+	t.Run("should fail when an analyzer fails", func(t *testing.T) {
+		_, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{})
+
+		if !errors.Is(err, domain.ErrAnalysisFailed) {
+			t.Fatalf("Expected ErrAnalysisFailed, got %v", err)
+		}
+	})
+
+	t.Run("should report failing analyzers as warnings in partial mode", func(t *testing.T) {
+		report, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{Partial: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if report.Analyses["ok"] != 42 {
+			t.Errorf("Expected the ok analyzer result, got %v", report.Analyses)
+		}
+		if len(report.Warnings) != 1 || report.Warnings[0].Field != "failing" || report.Warnings[0].Status != model.FieldStatusFailed {
+			t.Errorf("Expected a warning for the failing analyzer, got %+v", report.Warnings)
+		}
+	})
+
+	t.Run("should return error for unknown analyzers", func(t *testing.T) {
+		_, err := service.GenerateWebPageReport(context.Background(), srv.URL, model.ReportOptions{Analyzers: []string{"unknown"}})
+
+		if !errors.Is(err, domain.ErrUnknownAnalyzer) {
+			t.Fatalf("Expected ErrUnknownAnalyzer, got %v", err)
+		}
+	})
+}
+
+// stubFetcher answers every request with an empty page, so that benchmarks
+// measure the analysis rather than the network.
+type stubFetcher struct{}
+
+func (f stubFetcher) Fetch(ctx context.Context, method string, location string, opts model.FetchOptions) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+// generateLargePage returns a page with sections sections, each one with
+// headers, paragraphs, internal and external links and a form. All links point
+// to a handful of distinct URLs on baseURL, so link checking stays cheap.
+func generateLargePage(sections int, baseURL string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><head><title>Large page</title></head><body>")
+	for i := range sections {
+		fmt.Fprintf(&b, "<section><h2>Section %d</h2><h3>Subsection</h3>", i)
+		fmt.Fprintf(&b, "<p>Paragraph <a href=\"/page/%d\">internal</a> <a href=\"%v/page/%d\">external</a></p>", i%5, baseURL, i%5)
+		b.WriteString("<form><input type=\"email\"><input type=\"password\"></form></section>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func BenchmarkGenerateWebPageReport(b *testing.B) {
+	const pageURL = "http://localhost/"
+	prsr := parser.NewWebPageParser(stubFetcher{}, parser.Config{})
+	registry, err := domain.NewRegistry(parser.BuiltInAnalyzers(stubFetcher{}))
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, sections := range []int{1000, 10000} {
+		// The links are external because 127.0.0.1 and localhost are different hostnames.
+		page := generateLargePage(sections, "http://127.0.0.1")
+
+		modes := []struct {
+			name        string
+			concurrency int
+		}{
+			{"sequential", 1},
+			{"parallel", 0},
+		}
+		for _, mode := range modes {
+			b.Run(fmt.Sprintf("%v/sections=%d", mode.name, sections), func(b *testing.B) {
+				service := domain.NewService(prsr, registry, domain.Config{Concurrency: mode.concurrency})
+				for b.Loop() {
+					// Every iteration analyses a document of its own, parsed
+					// outside of the timer, so that nothing computed by a
+					// previous one is reused.
+					b.StopTimer()
+					doc, err := prsr.FromString(page, pageURL)
+					if err != nil {
+						b.Fatalf("Failed to load document: %v", err)
+					}
+					b.StartTimer()
+					if _, err := service.GenerateDocumentReport(context.Background(), doc, model.ReportOptions{}); err != nil {
+						b.Fatalf("Unexpected error: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateWebPageReport_Progress(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<!DOCTYPE html><html><body><h1>Header</h1><a href=\"/\">Home</a></body></html>")
+	}))
+	defer srv.Close()
+	service := newService(t, domain.Config{})
+
+	generate := func(opts model.ReportOptions) ([]model.ReportStage, error) {
+		// Progress is never called concurrently, so stages needs no lock.
+		var stages []model.ReportStage
+		opts.Progress = func(stage model.ReportStage) {
+			stages = append(stages, stage)
+		}
+		_, err := service.GenerateWebPageReport(context.Background(), srv.URL, opts)
+		return stages, err
+	}
+
+	t.Run("should report every stage once", func(t *testing.T) {
+		stages, err := generate(model.ReportOptions{Partial: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []model.ReportStage{
+			model.StageDownloaded, model.StageParsed, model.StageDocument, model.StageTitle,
+			model.StageLinks, model.StageLinkChecks, model.StageHeadings,
+		}
+		if len(stages) != len(expected) || stages[0] != model.StageDownloaded || stages[1] != model.StageParsed {
+			t.Fatalf("Expected %v, got %v", expected, stages)
+		}
+		for _, stage := range expected {
+			if !slices.Contains(stages, stage) {
+				t.Errorf("Expected stage %v to be reported, got %v", stage, stages)
+			}
+		}
+	})
+
+	t.Run("should not report the stages of failed metrics", func(t *testing.T) {
+		stages, err := generate(model.ReportOptions{})
+
+		if !errors.Is(err, domain.ErrAnalysisFailed) {
+			t.Fatalf("Expected ErrAnalysisFailed, got %v", err)
+		}
+		if slices.Contains(stages, model.StageTitle) {
+			t.Fatalf("Expected the title stage not to be reported, got %v", stages)
+		}
+	})
+}
+
+func TestValidateOptions(t *testing.T) {
+	t.Parallel()
+	service := newService(t, domain.Config{}, stubAnalyzer{name: "ok"})
+	tests := []struct {
+		name        string
+		opts        model.ReportOptions
+		expectedErr error
+	}{
+		{name: "valid options", opts: model.ReportOptions{Analyzers: []string{"ok"}, Fetch: model.FetchOptions{Proxy: "http://proxy:3128"}}},
+		{name: "unknown analyzer", opts: model.ReportOptions{Analyzers: []string{"unknown"}}, expectedErr: domain.ErrUnknownAnalyzer},
+		{name: "invalid proxy", opts: model.ReportOptions{Fetch: model.FetchOptions{Proxy: "proxy:3128"}}, expectedErr: ports.ErrInvalidFetchOptions},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			err := service.ValidateOptions(tcase.opts)
+
+			if !errors.Is(err, tcase.expectedErr) {
+				t.Fatalf("Expected %v, got %v", tcase.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// Analyzer is a self-contained check that is run against a downloaded document.
+// Every part of the report, the built-in fields included, is filled by an
+// analyzer, so new checks can be added by implementing it and registering it,
+// without touching the rest of the application.
+type Analyzer interface {
+	// Name identifies the analyzer in requests and in the warnings of partial
+	// reports. It must be unique.
+	Name() string
+	// Analyze returns the result of the check. It returns an error wrapping
+	// ErrNotFound or ErrNotStated when the document does not contain what the
+	// analyzer looks for.
+	Analyze(ctx context.Context, document Document) (Result, error)
+}
+
+// Result is what an analyzer found in a document.
+type Result interface {
+	// Apply adds the result to report. Results are applied one at a time, once
+	// every analyzer is done, so Apply needs no locking.
+	Apply(report *model.WebPageReport)
+}
+
+// StagedAnalyzer is implemented by the analyzers whose completion is reported
+// as a stage other than model.StageAnalyses.
+type StagedAnalyzer interface {
+	Analyzer
+	Stage() model.ReportStage
+}
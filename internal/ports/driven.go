@@ -1,16 +1,67 @@
 package ports
 
+import (
+	"context"
+	"net/http"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+	"golang.org/x/net/html"
+)
+
+// ReportRepository stores generated reports. Implementations must be safe for
+// concurrent use.
+type ReportRepository interface {
+	Save(ctx context.Context, report model.WebPageReport) error
+	// FindByID returns an error wrapping ErrReportNotFound when there is no
+	// report with the given ID.
+	FindByID(ctx context.Context, id string) (model.WebPageReport, error)
+	// FindByURL returns the reports of url, or all of them when url is empty,
+	// newest first.
+	FindByURL(ctx context.Context, url string) ([]model.WebPageReport, error)
+}
+
 type DocumentParser interface {
-	DownloadDocument(location string) error
-	GetDocumentVersion() (string, error)
-	GetTitle() (string, error)
-	GetExternalLinkCount() (int, error)
-	GetInternalLinkCount() (int, error)
-	GetContainsLogin() (bool, error)
-	GetHeaderOneCount() (int, error)
-	GetHeaderTwoCount() (int, error)
-	GetHeaderThreeCount() (int, error)
-	GetHeaderFourCount() (int, error)
-	GetHeaderFiveCount() (int, error)
-	GetHeaderSixCount() (int, error)
+	// DownloadDocument returns a new Document on every call, so implementations
+	// must not keep any state about the downloaded documents.
+	DownloadDocument(ctx context.Context, location string, opts model.FetchOptions) (Document, error)
+}
+
+// Fetcher sends HTTP requests on behalf of the parser and the analyzers, so
+// that all of them share the same configuration. Implementations must be safe
+// for concurrent use.
+type Fetcher interface {
+	// Fetch sends a request without body to location. The caller must close the
+	// body of the response. It returns an error wrapping ErrInvalidFetchOptions
+	// when opts cannot be used.
+	Fetch(ctx context.Context, method string, location string, opts model.FetchOptions) (*http.Response, error)
+}
+
+// Document is an immutable parsed document. All of its methods are safe to
+// call from several goroutines.
+type Document interface {
+	// URL is the location the document was downloaded from, once every
+	// redirect has been followed.
+	URL() string
+	// Redirects lists the redirects followed to download the document, in order.
+	Redirects() []model.Redirect
+	// ResponseInfo describes the response the document was served with, or is
+	// nil when it was not downloaded.
+	ResponseInfo() *model.ResponseInfo
+	// TLS describes the connection the document was downloaded over, or is nil
+	// when it was not downloaded over HTTPS.
+	TLS() *model.TLSInfo
+	// Header holds the headers of the response the document was served with,
+	// or is nil when it was not downloaded. It must not be modified.
+	Header() http.Header
+	// Root is the root node of the parsed document. It must not be modified.
+	Root() *html.Node
+	// Charset is the name of the encoding the document was decoded from, such
+	// as "utf-8" or "windows-1252".
+	Charset() string
+	// Links are the href attributes of the <a> elements of the document, in
+	// document order. They must not be modified.
+	Links() []string
+	// FetchOptions are the options the document was downloaded with, which the
+	// requests sent on its behalf, such as link checks, use too.
+	FetchOptions() model.FetchOptions
 }
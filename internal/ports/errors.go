@@ -0,0 +1,44 @@
+package ports
+
+import "errors"
+
+// Errors that DocumentParser implementations wrap when a document cannot be
+// downloaded, so that callers can tell the different failures apart with errors.Is.
+var ErrUnreachableHost = errors.New("host could not be reached")
+var ErrUpstreamClientError = errors.New("page responded with a client error")
+var ErrUpstreamServerError = errors.New("page responded with a server error")
+var ErrNotHTML = errors.New("page is not an HTML document")
+var ErrDocumentTooLarge = errors.New("page is too large")
+
+// ErrInvalidFetchOptions is wrapped by Fetcher implementations when the
+// options of a request cannot be used, such as a malformed proxy URL.
+var ErrInvalidFetchOptions = errors.New("invalid fetch options")
+
+// ErrForbiddenURL is wrapped by Fetcher implementations when the URL, or one
+// it redirects to, is not allowed to be fetched, such as one on a private network.
+var ErrForbiddenURL = errors.New("URL is not allowed")
+
+// ErrTooManyRedirects is wrapped by Fetcher implementations when a URL
+// redirects more times than they follow.
+var ErrTooManyRedirects = errors.New("page redirected too many times")
+
+// ErrReportNotFound is wrapped by ReportRepository implementations when there
+// is no report with the requested ID.
+var ErrReportNotFound = errors.New("report not found")
+
+// Errors returned by JobService implementations.
+var ErrJobNotFound = errors.New("job not found")
+var ErrQueueFull = errors.New("too many reports are queued")
+
+// ErrBatchTooLarge is returned by BatchService implementations when a batch
+// has more URLs than they accept.
+var ErrBatchTooLarge = errors.New("batch has too many URLs")
+
+// ErrNotFound is wrapped by Analyzer implementations when the document does not
+// contain what was asked for, as opposed to the document not being queryable.
+var ErrNotFound = errors.New("not found in document")
+
+// ErrNotStated is wrapped by Analyzer implementations when the document does
+// not state a value, such as a page without a doctype, rather than missing an
+// element.
+var ErrNotStated = errors.New("not stated by document")
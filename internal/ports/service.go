@@ -1,7 +1,44 @@
 package ports
 
-import "github.com/G-Fuchter/home24-assignment/internal/domain/model"
+import (
+	"context"
+
+	"github.com/G-Fuchter/home24-assignment/internal/domain/model"
+)
+
+// ReportGenerator generates reports without storing them.
+type ReportGenerator interface {
+	GenerateWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.WebPageReport, error)
+	// ValidateOptions returns the error GenerateWebPageReport would fail with
+	// because of opts alone, such as one wrapping ErrInvalidFetchOptions, so
+	// that reports generated later can be rejected right away.
+	ValidateOptions(opts model.ReportOptions) error
+}
 
 type Service interface {
-	GenerateWebPageReport(location string) (model.WebPageReport, error)
+	ReportGenerator
+	// GetWebPageReport returns a report that was generated before, or an error
+	// wrapping ErrReportNotFound.
+	GetWebPageReport(ctx context.Context, id string) (model.WebPageReport, error)
+	// ListWebPageReports returns the reports generated for url, or all of them
+	// when url is empty, newest first.
+	ListWebPageReports(ctx context.Context, url string) ([]model.WebPageReport, error)
+}
+
+// BatchService generates the reports of many URLs at once.
+type BatchService interface {
+	// GenerateWebPageReports returns one result per location, in the same order.
+	// A location that fails does not fail the rest of the batch. It returns an
+	// error wrapping ErrBatchTooLarge when there are too many locations.
+	GenerateWebPageReports(ctx context.Context, locations []string, opts model.ReportOptions) ([]model.BatchResult, error)
+}
+
+// JobService generates reports in the background.
+type JobService interface {
+	// SubmitWebPageReport queues a report and returns its job right away. It
+	// returns an error wrapping ErrQueueFull when no more jobs can be queued.
+	SubmitWebPageReport(ctx context.Context, location string, opts model.ReportOptions) (model.ReportJob, error)
+	// GetReportJob returns an error wrapping ErrJobNotFound when there is no job
+	// with the given ID.
+	GetReportJob(ctx context.Context, id string) (model.ReportJob, error)
 }